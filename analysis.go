@@ -0,0 +1,213 @@
+package ffmpeg_go
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// logTailLines is the number of stderr lines kept for the error of a failed
+// analysis run.
+const logTailLines = 10
+
+// logLineWriter calls parse for every line ffmpeg writes to stderr, and
+// keeps the last lines for error messages.
+type logLineWriter struct {
+	mu    sync.Mutex
+	parse func(line string)
+	buf   []byte
+	tail  []string
+}
+
+func (w *logLineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.buf = append(w.buf, p...)
+	for {
+		// progress lines end with \r, everything else with \n
+		i := bytes.IndexAny(w.buf, "\r\n")
+		if i < 0 {
+			break
+		}
+		w.line(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+func (w *logLineWriter) line(line string) {
+	if line == "" {
+		return
+	}
+	if w.parse != nil {
+		w.parse(line)
+	}
+	w.tail = append(w.tail, line)
+	if len(w.tail) > logTailLines {
+		w.tail = w.tail[1:]
+	}
+}
+
+func (w *logLineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.line(string(w.buf))
+	w.buf = nil
+}
+
+// runWithLogParser runs out with its stderr passed to parse line by line, a
+// failed run returns a *CommandError with the last lines of stderr.
+func runWithLogParser(out *Stream, parse func(line string)) error {
+	w := &logLineWriter{parse: parse}
+	err := out.WithErrorOutput(w).Run()
+	w.flush()
+	if err != nil {
+		cmdErr, ok := err.(*CommandError)
+		if !ok {
+			cmdErr = &CommandError{Path: out.FfmpegPath, Err: err}
+		}
+		cmdErr.Stderr = strings.Join(w.tail, "\n")
+		return cmdErr
+	}
+	return nil
+}
+
+// nullOutput outputs streams to the null muxer, for runs that are only
+// interesting for what ffmpeg logs.
+func nullOutput(ctx context.Context, ffmpegPath string, streams []*Stream, kwargs ...KwArgs) *Stream {
+	o := OutputContext(ctx, streams, "-", MergeKwArgs(kwargs), KwArgs{"format": "null"})
+	o.FfmpegPath = ffmpegPath
+	return o
+}
+
+// videoOf selects the video of an input, other streams are returned as is.
+func videoOf(s *Stream) *Stream {
+	if s.Node.nodeType == "InputNode" && s.Selector == "" {
+		return s.Video()
+	}
+	return s
+}
+
+// audioOf selects the audio of an input, other streams are returned as is.
+func audioOf(s *Stream) *Stream {
+	if s.Node.nodeType == "InputNode" && s.Selector == "" {
+		return s.Audio()
+	}
+	return s
+}
+
+// mediaOf tells if filters of s should apply to its video, audio or both:
+// both for an input without selector, audio if s is selected as audio, e.g.
+// with "a" or "a:1", and video for any other stream.
+func mediaOf(s *Stream) (video, audio bool) {
+	if s.Node.nodeType == "InputNode" && s.Selector == "" {
+		return true, true
+	}
+	audio = selectorMedia(string(s.Selector)) == "a"
+	return !audio, audio
+}
+
+// selectorMedia returns the media type a stream specifier selects, e.g. "a"
+// for "a", "a:0" or "1:a:1", and "v" for "v" or "V". It is empty if the
+// specifier doesn't select by media type, e.g. "0:1".
+func selectorMedia(selector string) string {
+	parts := strings.Split(selector, ":")
+	if len(parts) > 1 && parts[0] != "" && strings.Trim(parts[0], "0123456789") == "" {
+		// the index of the input
+		parts = parts[1:]
+	}
+	switch parts[0] {
+	case "v", "V":
+		return "v"
+	case "a", "s", "d", "t":
+		return parts[0]
+	}
+	return ""
+}
+
+// Interval is a time range of a media file.
+type Interval struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+func (i Interval) Duration() time.Duration {
+	return i.End - i.Start
+}
+
+// parseClock parses the “HH:MM:SS.xx“ durations ffmpeg logs.
+func parseClock(s string) (time.Duration, bool) {
+	l := strings.Split(strings.TrimSuffix(s, ","), ":")
+	if len(l) != 3 {
+		return 0, false
+	}
+	var seconds float64
+	for _, a := range l {
+		f, err := strconv.ParseFloat(a, 64)
+		if err != nil {
+			return 0, false
+		}
+		seconds = seconds*60 + f
+	}
+	return secondsToDuration(seconds), true
+}
+
+// durationParser finds the length of the processed media from stderr, which
+// is the time of the last progress line, or the input duration as fallback.
+type durationParser struct {
+	input    time.Duration
+	progress time.Duration
+}
+
+func (p *durationParser) parse(line string) {
+	if v, ok := logValue(line, "Duration: "); ok {
+		if d, ok := parseClock(v); ok && p.input == 0 {
+			p.input = d
+		}
+	} else if strings.HasPrefix(line, "frame=") || strings.HasPrefix(line, "size=") {
+		if v, ok := logValue(line, "time="); ok {
+			if d, ok := parseClock(v); ok {
+				p.progress = d
+			}
+		}
+	}
+}
+
+func (p *durationParser) duration() time.Duration {
+	if p.progress > 0 {
+		return p.progress
+	}
+	return p.input
+}
+
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+// logValue returns the value following key in an ffmpeg log line, e.g.
+// logValue("pts:35 pts_time:1.4", "pts_time:") returns "1.4".
+func logValue(line, key string) (string, bool) {
+	i := strings.Index(line, key)
+	if i < 0 {
+		return "", false
+	}
+	value := line[i+len(key):]
+	value = strings.TrimLeft(value, " ")
+	if j := strings.IndexAny(value, " |\t"); j >= 0 {
+		value = value[:j]
+	}
+	return value, true
+}
+
+// logFloat is logValue parsed as float.
+func logFloat(line, key string) (float64, bool) {
+	value, ok := logValue(line, key)
+	if !ok {
+		return 0, false
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	return f, err == nil
+}
@@ -0,0 +1,266 @@
+package ffmpeg_go
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectScenes(t *testing.T) {
+	ffmpeg := fakeFfmpeg(t, `
+echo "$@" > "$(dirname "$0")/args"
+echo '[Parsed_metadata_1 @ 0x7f9] frame:0    pts:35      pts_time:1.4' >&2
+echo '[Parsed_metadata_1 @ 0x7f9] lavfi.scene_score=0.521399' >&2`)
+	cuts, err := DetectScenes(context.Background(), Input("in.mp4").SetFfmpegPath(ffmpeg), 0.4)
+	assert.Nil(t, err)
+	assert.Equal(t, []SceneCut{{Time: 1400 * time.Millisecond, Score: 0.521399}}, cuts)
+	assert.Equal(t, "-i in.mp4 -filter_complex [0:v]select=gt(scene\\,0.4)[s0];[s0]metadata=print[s1] -map [s1] -f null -\n",
+		fakeFfmpegArgs(t, ffmpeg))
+}
+
+func TestDetectScenesThumbnailsOfReader(t *testing.T) {
+	ffmpeg := fakeFfmpeg(t, `echo "$@" > "$(dirname "$0")/args"`)
+	for _, input := range []*Stream{
+		InputReader(strings.NewReader("video"), KwArgs{"format": "h264"}),
+		Input("mem://in.mp4"),
+		Input("pipe:", KwArgs{"format": "h264"}),
+	} {
+		_, err := DetectScenes(context.Background(), input.SetFfmpegPath(ffmpeg), 0.4, SceneOptions{Thumbnails: true})
+		assert.EqualError(t, err, "scene thumbnails need file inputs, the input is read again for them")
+	}
+	_, err := os.Stat(filepath.Join(filepath.Dir(ffmpeg), "args"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestAnalysisError(t *testing.T) {
+	ffmpeg := fakeFfmpeg(t, "echo 'in.mp4: No such file or directory' >&2; exit 1")
+	_, err := DetectScenes(context.Background(), Input("in.mp4").SetFfmpegPath(ffmpeg), 0.4)
+	assert.EqualError(t, err, "[in.mp4: No such file or directory] exit status 1")
+}
+
+func TestSplitOnSilence(t *testing.T) {
+	ffmpeg := fakeFfmpeg(t, `
+case "$*" in
+*silencedetect*)
+	echo '  Duration: 00:00:12.00, start: 0.000000, bitrate: 128 kb/s' >&2
+	echo '[silencedetect @ 0x7f9] silence_start: 6' >&2
+	echo '[silencedetect @ 0x7f9] silence_end: 7 | silence_duration: 1' >&2
+	;;
+*)
+	echo "$@" > "$(dirname "$0")/args"
+	echo chunk0 >&3
+	echo chunk1 >&4
+	;;
+esac`)
+	chunks, err := SplitOnSilence(context.Background(), Input("in.mp4").SetFfmpegPath(ffmpeg), 10*time.Second, "")
+	assert.Nil(t, err)
+	assert.Equal(t, []AudioChunk{
+		{Interval: Interval{Start: 0, End: 6500 * time.Millisecond}, Data: []byte("chunk0\n")},
+		{Interval: Interval{Start: 6500 * time.Millisecond, End: 12 * time.Second}, Data: []byte("chunk1\n")},
+	}, chunks)
+	assert.Equal(t, "-i in.mp4 -filter_complex "+
+		"[0:a]asplit=2[s0][s1];"+
+		"[s0]atrim=end=6.5:start=0[s2];[s2]asetpts=PTS-STARTPTS[s3];"+
+		"[s1]atrim=end=12:start=6.5[s4];[s4]asetpts=PTS-STARTPTS[s5] "+
+		"-map [s3] -f wav pipe:3 -map [s5] -f wav pipe:4 -y\n", fakeFfmpegArgs(t, ffmpeg))
+}
+
+func TestNormalizeLoudness(t *testing.T) {
+	ffmpeg := fakeFfmpeg(t, "cat >&2 <<'EOF'\n"+testLoudnormLog+"\nEOF")
+	normalized, err := Input("in.wav").SetFfmpegPath(ffmpeg).NormalizeLoudness(LoudnessTarget{I: -16, TP: -1.5, LRA: 11})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"-i", "in.wav", "-filter_complex",
+		"[0:a]loudnorm=I=-16:LRA=11:TP=-1.5:linear=true:measured_I=-27.61:measured_LRA=18.06:" +
+			"measured_TP=-4.47:measured_thresh=-39.2:offset=0.58:print_format=summary[s0]",
+		"-map", "[s0]", "out.wav"}, normalized.Output("out.wav").GetArgs())
+}
+
+func TestAnalyzeVideo(t *testing.T) {
+	ffmpeg := fakeFfmpeg(t, "echo \"$@\" > \"$(dirname \"$0\")/args\"\ncat >&2 <<'EOF'\n"+testQCLog+"\nEOF")
+	report, err := AnalyzeVideo(context.Background(), Input("in.mp4").SetFfmpegPath(ffmpeg), QCOptions{
+		Black:  &BlackDetectOptions{MinDuration: time.Second},
+		Freeze: &FreezeDetectOptions{Noise: -60},
+		Crop:   &CropDetectOptions{},
+	})
+	assert.Nil(t, err)
+	assert.Len(t, report.Black, 1)
+	assert.Len(t, report.Freeze, 2)
+	assert.Equal(t, "-i in.mp4 -filter_complex "+
+		"[0:v]split=3[s0][s1][s2];[s0]blackdetect=d=1[s3];[s1]freezedetect=n=-60dB[s4];[s2]cropdetect[s5] "+
+		"-map [s3] -map [s4] -map [s5] -f null -\n", fakeFfmpegArgs(t, ffmpeg))
+
+	cropped, err := Input("in.mp4").SetFfmpegPath(ffmpeg).AutoCrop()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"-i", "in.mp4", "-filter_complex", "[0:v]crop=1920:800:0:140[s0]", "-map", "[s0]", "out.mp4"},
+		cropped.Output("out.mp4").GetArgs())
+}
+
+func TestVerifyDecodable(t *testing.T) {
+	ffmpeg := fakeFfmpeg(t, "echo \"$@\" > \"$(dirname \"$0\")/args\"\ncat >&2 <<'EOF'\n"+testDecodeLog+"\nEOF\nexit 1")
+	report, err := VerifyDecodable(context.Background(), Input("in.mp4").SetFfmpegPath(ffmpeg), VerifyOptions{ExitOnError: true})
+	assert.Nil(t, err)
+	assert.False(t, report.OK)
+	assert.Len(t, report.Errors, 4)
+	assert.Equal(t, 4*time.Second, report.Errors[3].Time)
+	assert.Equal(t, "-i in.mp4 -f null -map 0 - -loglevel repeat+level+info -xerror\n", fakeFfmpegArgs(t, ffmpeg))
+
+	report, err = VerifyDecodable(context.Background(), Input("in.mp4").SetFfmpegPath(fakeFfmpeg(t, "exit 0")))
+	assert.Nil(t, err)
+	assert.True(t, report.OK)
+
+	_, err = VerifyDecodable(context.Background(), Input("in.mp4").SetFfmpegPath(fakeFfmpeg(t, "exit 1")))
+	assert.NotNil(t, err)
+
+	ffmpeg = fakeFfmpeg(t, "echo '[in#0 @ 0x55d] [error] Error opening input: No such file or directory' >&2\n"+
+		"echo '[error] Error opening input file missing.mp4.' >&2\nexit 254")
+	_, err = VerifyDecodable(context.Background(), Input("missing.mp4").SetFfmpegPath(ffmpeg))
+	var cmdErr *CommandError
+	assert.True(t, errors.As(err, &cmdErr))
+	assert.Equal(t, 254, cmdErr.ExitCode())
+	assert.Contains(t, cmdErr.Stderr, "No such file or directory")
+}
+
+func TestCompareQuality(t *testing.T) {
+	ffmpeg := fakeFfmpeg(t, `
+if [ "$2" = -filters ]; then
+	echo ' ... psnr              VV->V      Calculate the PSNR between two video streams.'
+	exit 0
+fi
+echo "$@" > "$(dirname "$0")/args"
+for arg in "$@"; do
+	case "$arg" in *psnr*) file=$(echo "$arg" | sed 's/.*psnr=stats_file=\([^[]*\)\[.*/\1/; s/\\\\//g');; esac
+done
+echo 'n:1 mse_avg:0.52 mse_y:0.69 mse_u:0.18 mse_v:0.20 psnr_avg:50.96 psnr_y:49.72 psnr_u:55.55 psnr_v:55.12' > "$file"
+echo '[Parsed_psnr_3 @ 0x7f9] PSNR y:49.72 u:55.55 v:55.12 average:50.96 min:50.96 max:50.96' >&2`)
+	report, err := CompareQuality(context.Background(),
+		Input("ref.mp4").SetFfmpegPath(ffmpeg), Input("dist.mp4"), Metrics{PSNR: true, VMAF: true, FrameRate: "25"})
+	assert.Nil(t, err)
+	assert.True(t, report.VMAFSkipped)
+	assert.Equal(t, 50.96, report.PSNR)
+	assert.Equal(t, []FrameQuality{{Frame: 0, PSNR: 50.96}}, report.Frames)
+	assert.Regexp(t, `^-i dist.mp4 -i ref.mp4 -filter_complex `+
+		`\[0:v\]fps=25\[s0\];\[s0\]\[1:v\]scale2ref\[s1\]\[s2\];\[s1\]\[s2\]psnr=stats_file=.*psnr.log\[s3\] `+
+		`-map \[s3\] -f null -`, fakeFfmpegArgs(t, ffmpeg))
+}
+
+func TestSpriteSheet(t *testing.T) {
+	ffmpeg := fakeFfmpeg(t, `echo "$@" > "$(dirname "$0")/args"`)
+	ffprobe := fakeFfmpeg(t, `echo '{"format": {"duration": "12.5"}}'`)
+	SetFfprobePath(ffprobe)
+	defer SetFfprobePath("ffprobe")
+	dir := t.TempDir()
+	sprites, err := Input("in.mp4").SetFfmpegPath(ffmpeg).SpriteSheet(context.Background(), SpriteOptions{
+		Interval: 5 * time.Second, TileW: 160, TileH: 90, Columns: 2, Rows: 1, Dir: dir, URLPrefix: "/media/",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "-i in.mp4 -filter_complex [0:v]fps=fps=1/5[s0];[s0]scale=160:90[s1];[s1]tile=2x1[s2] -map [s2] "+
+		filepath.Join(dir, "sprite_%03d.jpg")+"\n", fakeFfmpegArgs(t, ffmpeg))
+	assert.Equal(t, []string{filepath.Join(dir, "sprite_001.jpg"), filepath.Join(dir, "sprite_002.jpg")}, sprites.Sheets)
+	vtt, err := ioutil.ReadFile(sprites.VTT)
+	assert.Nil(t, err)
+	assert.Contains(t, string(vtt), "00:00:10.000 --> 00:00:12.500\n/media/sprite_002.jpg#xywh=0,0,160,90\n")
+}
+
+func TestWriteGIFBudget(t *testing.T) {
+	// writes 1000 bytes per fps to the gif argument
+	ffmpeg := fakeFfmpeg(t, `
+for arg; do case "$arg" in *.gif) out=$arg;; esac; done
+fps=$(echo "$@" | sed 's/.*fps=\([0-9]*\).*/\1/')
+echo "$@" >> "$(dirname "$0")/args"
+head -c $((fps * 1000)) /dev/zero > "$out"`)
+	out := filepath.Join(t.TempDir(), "out.gif")
+	opts, err := Input("in.mp4").SetFfmpegPath(ffmpeg).WriteGIF(context.Background(), out, GIFOptions{MaxBytes: 8000})
+	assert.Nil(t, err)
+	assert.Equal(t, 8.0, opts.FPS)
+	args := fakeFfmpegArgs(t, ffmpeg)
+	assert.Equal(t, 4, strings.Count(args, "\n"))
+	// every run overwrites the gif of the previous one
+	assert.Equal(t, 4, strings.Count(args, " -y\n"))
+
+	_, err = Input("in.mp4").SetFfmpegPath(ffmpeg).WriteGIF(context.Background(), out, GIFOptions{MaxBytes: 10})
+	assert.EqualError(t, err, "gif of 4000 bytes exceeds the budget of 10 bytes")
+}
+
+func TestConcatFiles(t *testing.T) {
+	ffmpeg := fakeFfmpeg(t, `
+echo "$@" > "$(dirname "$0")/args"
+for arg; do case "$arg" in *.txt) cp "$arg" "$(dirname "$0")/list";; esac; done`)
+	ffprobe := fakeFfmpeg(t, `
+case "$*" in
+*b.mp4) echo '{"format": {"duration": "4"}, "streams": [{"codec_type": "video", "codec_name": "hevc", "width": 640, "height": 360}]}';;
+*) echo '{"format": {"duration": "4"}, "streams": [{"codec_type": "video", "codec_name": "h264", "width": 640, "height": 360}]}';;
+esac`)
+	SetFfprobePath(ffprobe)
+	defer SetFfprobePath("ffprobe")
+
+	copied, err := ConcatFiles(context.Background(), []string{"/a.mp4", "/c.mp4"}, "out.mp4", ConcatOptions{
+		FfmpegPath: ffmpeg, Outpoints: []time.Duration{2 * time.Second},
+	})
+	assert.Nil(t, err)
+	assert.True(t, copied)
+	assert.Regexp(t, `^-f concat -safe 0 -i \S+\.txt -c copy -map 0 out.mp4`, fakeFfmpegArgs(t, ffmpeg))
+	list, err := ioutil.ReadFile(filepath.Join(filepath.Dir(ffmpeg), "list"))
+	assert.Nil(t, err)
+	assert.Equal(t, "ffconcat version 1.0\nfile '/a.mp4'\noutpoint 2\nfile '/c.mp4'\n", string(list))
+
+	copied, err = ConcatFiles(context.Background(), []string{"/a.mp4", "/b.mp4"}, "out.mp4", ConcatOptions{FfmpegPath: ffmpeg})
+	assert.Nil(t, err)
+	assert.False(t, copied)
+	args := fakeFfmpegArgs(t, ffmpeg)
+	assert.Regexp(t, `^-i /a.mp4 -i /b.mp4 -filter_complex `, args)
+	assert.Contains(t, args, "concat=n=2")
+
+	copied, err = ConcatFiles(context.Background(), []string{"/a.mp4", "/c.mp4"}, "out.mp4", ConcatOptions{
+		FfmpegPath: fakeFfmpeg(t, "exit 1"),
+	})
+	var cmdErr *CommandError
+	assert.True(t, errors.As(err, &cmdErr))
+	assert.False(t, copied)
+}
+
+func TestCutLossless(t *testing.T) {
+	ffmpeg := fakeFfmpeg(t, `echo "$@" >> "$(dirname "$0")/args"`)
+	ffprobe := fakeFfmpeg(t, `
+case "$*" in
+*show_packets*) printf 'packet|stream_index=0|pts_time=%s|flags=K_\n' 0.000000 2.000000 4.000000 6.000000;;
+*vp9.webm) echo '{"format": {"duration": "7"}, "streams": [{"codec_type": "video", "codec_name": "vp9", "profile": "Profile 0"}]}';;
+*main12.mp4) echo '{"format": {"duration": "7"}, "streams": [{"codec_type": "video", "codec_name": "hevc", "profile": "Main 12", "level": 120}]}';;
+*) echo '{"format": {"duration": "7"}, "streams": [{"codec_type": "video", "codec_name": "h264", "profile": "High", "level": 31, "pix_fmt": "yuv420p", "time_base": "1/12800"}, {"codec_type": "audio", "codec_name": "aac"}]}';;
+esac`)
+	SetFfprobePath(ffprobe)
+	defer SetFfprobePath("ffprobe")
+
+	cut, err := CutLossless(context.Background(), "in.mp4", 3*time.Second, 5*time.Second, "out.mp4", CutOptions{FfmpegPath: ffmpeg})
+	assert.Nil(t, err)
+	assert.Equal(t, Interval{Start: 2 * time.Second, End: 6 * time.Second}, cut)
+	assert.Equal(t, "-ss 2 -i in.mp4 -avoid_negative_ts make_zero -c copy -map 0 -t 4 out.mp4 -y\n", fakeFfmpegArgs(t, ffmpeg))
+
+	assert.Nil(t, os.Remove(filepath.Join(filepath.Dir(ffmpeg), "args")))
+	_, err = CutLossless(context.Background(), "in.mp4", 3*time.Second, 6500*time.Millisecond, "out.mp4",
+		CutOptions{FfmpegPath: ffmpeg, Smart: true, KwArgs: KwArgs{"crf": 18}})
+	assert.Nil(t, err)
+	runs := strings.Split(strings.TrimSpace(fakeFfmpegArgs(t, ffmpeg)), "\n")
+	assert.Len(t, runs, 4)
+	assert.Regexp(t, `^-ss 3 -i in.mp4 -c:a aac -c:v libx264 -crf 18 -level 3.1 -map 0:v:0 -map 0:a\? -pix_fmt yuv420p -profile:v high -t 1 `+
+		`-video_track_timescale 12800 -x264-params repeat-headers=1 \S+/part0.mp4 -y$`, runs[0])
+	assert.Regexp(t, `^-ss 4 -i in.mp4 -avoid_negative_ts make_zero -c copy -map 0:v:0 -map 0:a\? -t 2 \S+/part1.mp4 -y$`, runs[1])
+	assert.Regexp(t, `^-ss 6 -i in.mp4 .* -t 0.5 .*\S+/part2.mp4 -y$`, runs[2])
+	assert.Regexp(t, `^-f concat -safe 0 -i \S+ -c copy -map 0 out.mp4 -y$`, runs[3])
+
+	// the parts can't be encoded like the input
+	assert.Nil(t, os.Remove(filepath.Join(filepath.Dir(ffmpeg), "args")))
+	for _, input := range []string{"vp9.webm", "main12.mp4"} {
+		_, err = CutLossless(context.Background(), input, 3*time.Second, 5*time.Second, "out"+filepath.Ext(input), CutOptions{FfmpegPath: ffmpeg, Smart: true})
+		assert.NotNil(t, err, input)
+		_, err = os.Stat(filepath.Join(filepath.Dir(ffmpeg), "args"))
+		assert.True(t, os.IsNotExist(err), input)
+	}
+}
@@ -0,0 +1,53 @@
+package ffmpeg_go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogLineWriter(t *testing.T) {
+	var lines []string
+	w := &logLineWriter{parse: func(line string) { lines = append(lines, line) }}
+	_, _ = w.Write([]byte("first\nsec"))
+	_, _ = w.Write([]byte("ond\r\nframe=1\rframe=2\rlast"))
+	w.flush()
+	assert.Equal(t, []string{"first", "second", "frame=1", "frame=2", "last"}, lines)
+}
+
+func TestLogValue(t *testing.T) {
+	line := "[Parsed_metadata_1 @ 0x7f9] frame:0    pts:35      pts_time:1.4"
+	v, ok := logValue(line, "pts:")
+	assert.True(t, ok)
+	assert.Equal(t, "35", v)
+	f, ok := logFloat(line, "pts_time:")
+	assert.True(t, ok)
+	assert.Equal(t, 1.4, f)
+	_, ok = logFloat(line, "duration:")
+	assert.False(t, ok)
+}
+
+func TestMediaOf(t *testing.T) {
+	for _, c := range []struct {
+		selector     string
+		video, audio bool
+	}{
+		{"a", false, true},
+		{"a:0", false, true},
+		{"0:a:1", false, true},
+		{"1:a", false, true},
+		{"v", true, false},
+		{"V:0", true, false},
+		{"0:1", true, false},
+		{"s:0", true, false},
+	} {
+		video, audio := mediaOf(Input("in.mp4").Get(c.selector))
+		assert.Equal(t, c.video, video, c.selector)
+		assert.Equal(t, c.audio, audio, c.selector)
+	}
+	video, audio := mediaOf(Input("in.mp4"))
+	assert.True(t, video && audio)
+
+	_, a := Input("in.mp4").Get("a:0").ChangeSpeed(2)
+	assert.Equal(t, "[0:a:0]atempo=2[s0]", a.Output("out.wav").GetArgs()[3])
+}
@@ -0,0 +1,192 @@
+package ffmpeg_go
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type AMixOptions struct {
+	// Weights are the weights of the inputs, missing weights are 1.
+	Weights []float64
+	// NoNormalize keeps the weighted inputs at their level, by default amix
+	// scales them down so that their sum doesn't clip.
+	NoNormalize bool
+	// Duration is the duration of the mix: "longest" (default), "shortest"
+	// or "first".
+	Duration string
+	// DropoutTransition is the time the volume is renormalized in when an
+	// input ends, default two seconds.
+	DropoutTransition time.Duration
+}
+
+// AMix mixes the audio streams into one with amix.
+func AMix(streams []*Stream, opts ...AMixOptions) *Stream {
+	if len(streams) == 0 {
+		panic("no streams to mix")
+	}
+	opt := AMixOptions{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if len(opt.Weights) > len(streams) {
+		panic("more weights than streams")
+	}
+	args := KwArgs{"inputs": len(streams)}
+	if len(opt.Weights) > 0 {
+		var weights []string
+		for i := range streams {
+			w := 1.0
+			if i < len(opt.Weights) {
+				w = opt.Weights[i]
+			}
+			weights = append(weights, formatFloat(w))
+		}
+		args["weights"] = strings.Join(weights, " ")
+	}
+	if opt.NoNormalize {
+		args["normalize"] = 0
+	}
+	if opt.Duration != "" {
+		args["duration"] = opt.Duration
+	}
+	if opt.DropoutTransition > 0 {
+		args["dropout_transition"] = formatSeconds(opt.DropoutTransition)
+	}
+	return NewFilterNode("amix", streams, -1, nil, args).Stream("", "")
+}
+
+// AMerge merges the channels of the audio streams into one multi-channel
+// stream with amerge, e.g. two mono streams into a stereo one.
+func AMerge(streams []*Stream) *Stream {
+	if len(streams) < 2 {
+		panic("amerge needs at least two streams")
+	}
+	return NewFilterNode("amerge", streams, -1, nil, KwArgs{"inputs": len(streams)}).Stream("", "")
+}
+
+// Pan remixes the channels into layout with pan, each channel is defined as
+// in “c0=0.5*c0+0.5*c1“ or “FL<FL+0.5*FC“.
+func (s *Stream) Pan(layout string, channels ...string) *Stream {
+	AssertType(s.Type, "FilterableStream", "pan")
+	if len(channels) == 0 {
+		panic("pan needs a definition of the channels")
+	}
+	args := strings.Join(append([]string{layout}, channels...), "|")
+	return NewFilterNode("pan", []*Stream{s}, 1, []string{args}, nil).Stream("", "")
+}
+
+// ChannelSplit splits s of channel layout into one stream per channel with
+// channelsplit, the outputs are got with Get("0"), Get("1")... If channels
+// are given only those are split out, in the order of layout.
+func (s *Stream) ChannelSplit(layout string, channels ...string) *Node {
+	AssertType(s.Type, "FilterableStream", "channelsplit")
+	args := KwArgs{"channel_layout": layout}
+	if len(channels) > 0 {
+		args["channels"] = strings.Join(channels, "|")
+	}
+	return NewFilterNode("channelsplit", []*Stream{s}, -1, nil, args)
+}
+
+// Downmix converts s to the channel layout, e.g. "stereo" or "mono", with
+// aformat, the channels are remixed by the resampler with the standard
+// downmix coefficients.
+func (s *Stream) Downmix(layout string) *Stream {
+	AssertType(s.Type, "FilterableStream", "downmix")
+	return NewFilterNode("aformat", []*Stream{s}, 1, nil, KwArgs{"channel_layouts": layout}).Stream("", "")
+}
+
+// AResample resamples s to sampleRate with aresample, kwargs are further
+// options such as {"async": 1}.
+func (s *Stream) AResample(sampleRate int, kwargs ...KwArgs) *Stream {
+	AssertType(s.Type, "FilterableStream", "aresample")
+	return NewFilterNode("aresample", []*Stream{s}, 1, []string{fmt.Sprint(sampleRate)}, MergeKwArgs(kwargs)).Stream("", "")
+}
+
+// VolumePoint is a point of a volume envelope, Gain is the linear volume
+// at time At.
+type VolumePoint struct {
+	At   time.Duration
+	Gain float64
+}
+
+// VolumeEnvelope changes the volume of s along points, the gain is
+// interpolated linearly between them and held before the first and after the
+// last point.
+func (s *Stream) VolumeEnvelope(points []VolumePoint) *Stream {
+	AssertType(s.Type, "FilterableStream", "volume")
+	if len(points) == 0 {
+		panic("volume envelope has no points")
+	}
+	return NewFilterNode("volume", []*Stream{s}, 1, nil, KwArgs{
+		"volume": volumeEnvelopeExpr(points),
+		"eval":   "frame",
+	}).Stream("", "")
+}
+
+func volumeEnvelopeExpr(points []VolumePoint) string {
+	points = append([]VolumePoint(nil), points...)
+	sort.SliceStable(points, func(i, j int) bool { return points[i].At < points[j].At })
+	last := points[len(points)-1]
+	expr := formatFloat(last.Gain)
+	for i := len(points) - 2; i >= 0; i-- {
+		p, next := points[i], points[i+1]
+		if next.At == p.At {
+			continue
+		}
+		segment := formatFloat(p.Gain)
+		if delta := next.Gain - p.Gain; delta != 0 {
+			sign := "+"
+			if delta < 0 {
+				sign, delta = "-", -delta
+			}
+			segment = fmt.Sprintf("%s%s%s*(t-%s)/%s", formatFloat(p.Gain), sign, formatFloat(delta),
+				formatSeconds(p.At), formatSeconds(next.At-p.At))
+		}
+		expr = fmt.Sprintf("if(lt(t,%s),%s,%s)", formatSeconds(next.At), segment, expr)
+	}
+	if points[0].At > 0 {
+		expr = fmt.Sprintf("if(lt(t,%s),%s,%s)", formatSeconds(points[0].At), formatFloat(points[0].Gain), expr)
+	}
+	return expr
+}
+
+// Track describes an output stream for TrackKwArgs.
+type Track struct {
+	// Spec is the output stream specifier, e.g. "a:0" or "s:1".
+	Spec string
+	// Language is the ISO 639-2 language code, e.g. "eng".
+	Language string
+	Title    string
+	// Disposition is e.g. "default", "default+forced" or "0" to clear the
+	// disposition copied from the input.
+	Disposition string
+}
+
+// TrackKwArgs returns the output options setting the metadata and
+// dispositions of tracks, e.g.
+//
+//	Output(streams, "out.mkv", TrackKwArgs(Track{Spec: "a:0", Language: "eng", Disposition: "default"}))
+func TrackKwArgs(tracks ...Track) KwArgs {
+	args := KwArgs{}
+	for _, t := range tracks {
+		if t.Spec == "" {
+			panic("track without stream specifier")
+		}
+		var metadata []string
+		if t.Language != "" {
+			metadata = append(metadata, "language="+t.Language)
+		}
+		if t.Title != "" {
+			metadata = append(metadata, "title="+t.Title)
+		}
+		if len(metadata) > 0 {
+			args["metadata:s:"+t.Spec] = metadata
+		}
+		if t.Disposition != "" {
+			args["disposition:"+t.Spec] = t.Disposition
+		}
+	}
+	return args
+}
@@ -0,0 +1,53 @@
+package ffmpeg_go
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAMix(t *testing.T) {
+	mixed := AMix([]*Stream{Input("a.wav"), Input("b.wav"), Input("c.wav")}, AMixOptions{
+		Weights:     []float64{1, 0.5},
+		NoNormalize: true,
+		Duration:    "first",
+	})
+	assert.Equal(t, "[0][1][2]amix=duration=first:inputs=3:normalize=0:weights=1 0.5 1[s0]", mixed.Output("out.wav").GetArgs()[7])
+	assert.Panics(t, func() { AMix([]*Stream{Input("a.wav")}, AMixOptions{Weights: []float64{1, 2}}) })
+
+	merged := AMerge([]*Stream{Input("l.wav"), Input("r.wav")})
+	assert.Equal(t, "[0][1]amerge=inputs=2[s0]", merged.Output("out.wav").GetArgs()[5])
+}
+
+func TestChannels(t *testing.T) {
+	in := Input("in.mp4").Audio()
+	assert.Equal(t, `[0:a]pan=stereo|c0\\=c1|c1\\=c0[s0]`, in.Pan("stereo", "c0=c1", "c1=c0").Output("out.wav").GetArgs()[3])
+	assert.Equal(t, "[0:a]aformat=channel_layouts=stereo[s0];[s0]aresample=48000:async=1[s1]", in.Downmix("stereo").AResample(48000, KwArgs{"async": 1}).Output("out.wav").GetArgs()[3])
+
+	split := in.ChannelSplit("5.1", "FL", "FR")
+	args := Output([]*Stream{split.Get("0"), split.Get("1")}, "out.mka").GetArgs()
+	assert.Equal(t, "[0:a]channelsplit=channel_layout=5.1:channels=FL|FR[s0][s1]", args[3])
+}
+
+func TestVolumeEnvelope(t *testing.T) {
+	expr := volumeEnvelopeExpr([]VolumePoint{
+		{At: 5 * time.Second, Gain: 0},
+		{At: time.Second, Gain: 1},
+		{At: 3 * time.Second, Gain: 1},
+	})
+	assert.Equal(t, "if(lt(t,1),1,if(lt(t,3),1,if(lt(t,5),1-1*(t-3)/2,0)))", expr)
+	args := Input("in.wav").VolumeEnvelope([]VolumePoint{{Gain: 0}, {At: 2 * time.Second, Gain: 1}}).Output("out.wav").GetArgs()
+	assert.Equal(t, `[0]volume=eval=frame:volume=if(lt(t\,2)\,0+1*(t-0)/2\,1)[s0]`, args[3])
+}
+
+func TestTrackKwArgs(t *testing.T) {
+	args := Output([]*Stream{Input("in.mkv")}, "out.mkv", TrackKwArgs(
+		Track{Spec: "a:0", Language: "eng", Title: "Director's commentary", Disposition: "default"},
+		Track{Spec: "a:1", Language: "ger", Disposition: "0"},
+	)).GetArgs()
+	assert.Equal(t, []string{"-i", "in.mkv",
+		"-disposition:a:0", "default", "-disposition:a:1", "0",
+		"-metadata:s:a:0", "language=eng", "-metadata:s:a:0", "title=Director's commentary", "-metadata:s:a:1", "language=ger",
+		"out.mkv"}, args)
+}
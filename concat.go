@@ -0,0 +1,170 @@
+package ffmpeg_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type ConcatOptions struct {
+	// SafeMode rejects unsafe file names in the concat demuxer (“safe=1“),
+	// the paths must then be relative to the working directory, where the
+	// list file is written to.
+	SafeMode bool
+	// Inpoints and Outpoints are the points of each path, zero or missing
+	// values use the whole file.
+	Inpoints  []time.Duration
+	Outpoints []time.Duration
+	// FfmpegPath is the ffmpeg binary, default "ffmpeg".
+	FfmpegPath string
+	// OverWriteOutput overwrites fileName if it exists (“-y“).
+	OverWriteOutput bool
+	// KwArgs are passed to the output verbatim, they apply to the re-encoding
+	// fallback too, e.g. {"movflags": "+faststart"}.
+	KwArgs KwArgs
+}
+
+// ConcatFiles joins the files of paths into fileName. If the probed streams
+// of all files match, they are stream copied with the concat demuxer (“-f
+// concat -c copy“), otherwise they are re-encoded with the concat filter of a
+// Timeline normalized to the first file. It returns whether the streams were
+// copied.
+func ConcatFiles(ctx context.Context, paths []string, fileName string, opts ConcatOptions) (bool, error) {
+	if len(paths) == 0 {
+		return false, errors.New("no files to concat")
+	}
+	if len(opts.Inpoints) > len(paths) || len(opts.Outpoints) > len(paths) {
+		return false, errors.New("more in or out points than files")
+	}
+	if opts.FfmpegPath == "" {
+		opts.FfmpegPath = "ffmpeg"
+	}
+	media := make([]probedMedia, len(paths))
+	for i, path := range paths {
+		m, err := probeMedia(ctx, path)
+		if err != nil {
+			return false, err
+		}
+		media[i] = m
+	}
+	if concatCompatible(media) != nil {
+		return false, concatFilterFiles(ctx, paths, media, fileName, opts)
+	}
+	// the streams count as copied only once the run succeeded
+	if err := concatDemuxer(ctx, paths, fileName, opts); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// concatDemuxer stream copies paths with the concat demuxer, without
+// checking that they are compatible.
+func concatDemuxer(ctx context.Context, paths []string, fileName string, opts ConcatOptions) error {
+	dir := ""
+	if opts.SafeMode {
+		dir = "."
+	}
+	list, err := ioutil.TempFile(dir, "ffmpeg_go_concat_*.txt")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(list.Name())
+	_, err = list.WriteString(concatList(paths, opts))
+	if closeErr := list.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	safe := 0
+	if opts.SafeMode {
+		safe = 1
+	}
+	in := Input(list.Name(), KwArgs{"f": "concat", "safe": safe})
+	o := OutputContext(ctx, []*Stream{in}, fileName, opts.KwArgs, KwArgs{"c": "copy", "map": "0"})
+	o.FfmpegPath = opts.FfmpegPath
+	if opts.OverWriteOutput {
+		o = o.OverWriteOutput()
+	}
+	return runWithLogParser(o, nil)
+}
+
+// concatList writes the concat demuxer list of paths. Unless in safe mode,
+// paths are made absolute, as they are resolved relative to the list file.
+func concatList(paths []string, opts ConcatOptions) string {
+	b := strings.Builder{}
+	b.WriteString("ffconcat version 1.0\n")
+	for i, path := range paths {
+		if !opts.SafeMode {
+			if abs, err := filepath.Abs(path); err == nil {
+				path = abs
+			}
+		}
+		fmt.Fprintf(&b, "file %s\n", quoteConcatPath(path))
+		if i < len(opts.Inpoints) && opts.Inpoints[i] > 0 {
+			fmt.Fprintf(&b, "inpoint %s\n", formatSeconds(opts.Inpoints[i]))
+		}
+		if i < len(opts.Outpoints) && opts.Outpoints[i] > 0 {
+			fmt.Fprintf(&b, "outpoint %s\n", formatSeconds(opts.Outpoints[i]))
+		}
+	}
+	return b.String()
+}
+
+// quoteConcatPath quotes path for the concat list: it is put in single
+// quotes, which can't be escaped within quotes, so a quote closes the quoted
+// part, is escaped and opens a new one.
+func quoteConcatPath(path string) string {
+	return "'" + strings.ReplaceAll(path, "'", `'\''`) + "'"
+}
+
+// concatCompatible checks that all files have the same streams with the same
+// codec parameters, which the concat demuxer requires for stream copy.
+func concatCompatible(media []probedMedia) error {
+	first := media[0]
+	for i, m := range media[1:] {
+		if len(m.Streams) != len(first.Streams) {
+			return fmt.Errorf("file %d has %d streams, not %d", i+1, len(m.Streams), len(first.Streams))
+		}
+		for j, s := range m.Streams {
+			f := first.Streams[j]
+			// the index and frame rate don't need to match
+			s.Index, f.Index, s.FrameRate, f.FrameRate = 0, 0, "", ""
+			if s != f {
+				return fmt.Errorf("stream %d of file %d differs", j, i+1)
+			}
+		}
+	}
+	return nil
+}
+
+// concatFilterFiles re-encodes paths as a Timeline.
+func concatFilterFiles(ctx context.Context, paths []string, media []probedMedia, fileName string, opts ConcatOptions) error {
+	t := Timeline{}
+	for i, path := range paths {
+		c := Clip{Source: Input(path).SetFfmpegPath(opts.FfmpegPath), Out: media[i].Duration}
+		if i < len(opts.Inpoints) {
+			c.In = opts.Inpoints[i]
+		}
+		if i < len(opts.Outpoints) && opts.Outpoints[i] > 0 {
+			c.Out = opts.Outpoints[i]
+		}
+		if _, ok := media[i].stream("audio"); !ok {
+			t.NoAudio = true
+		}
+		t.Clips = append(t.Clips, c)
+	}
+	o, err := t.Output(ctx, fileName, opts.KwArgs)
+	if err != nil {
+		return err
+	}
+	if opts.OverWriteOutput {
+		o = o.OverWriteOutput()
+	}
+	return runWithLogParser(o, nil)
+}
@@ -0,0 +1,40 @@
+package ffmpeg_go
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConcatList(t *testing.T) {
+	list := concatList([]string{"a.mp4", "it's.mp4"}, ConcatOptions{
+		SafeMode:  true,
+		Inpoints:  []time.Duration{0, 1500 * time.Millisecond},
+		Outpoints: []time.Duration{10 * time.Second},
+	})
+	assert.Equal(t, `ffconcat version 1.0
+file 'a.mp4'
+outpoint 10
+file 'it'\''s.mp4'
+inpoint 1.5
+`, list)
+
+	abs, err := filepath.Abs("a.mp4")
+	assert.Nil(t, err)
+	assert.Equal(t, "ffconcat version 1.0\nfile '"+abs+"'\n", concatList([]string{"a.mp4"}, ConcatOptions{}))
+}
+
+func TestConcatCompatible(t *testing.T) {
+	video := probedStream{CodecType: "video", CodecName: "h264", Width: 1280, Height: 720, PixFmt: "yuv420p", FrameRate: "25/1"}
+	audio := probedStream{Index: 1, CodecType: "audio", CodecName: "aac", SampleRate: "48000", Channels: 2}
+	a := probedMedia{Streams: []probedStream{video, audio}}
+	b := probedMedia{Streams: []probedStream{video, audio}}
+	b.Streams[0].FrameRate = "30/1"
+	assert.Nil(t, concatCompatible([]probedMedia{a, b}))
+
+	b.Streams[1].SampleRate = "44100"
+	assert.EqualError(t, concatCompatible([]probedMedia{a, b}), "stream 1 of file 1 differs")
+	assert.EqualError(t, concatCompatible([]probedMedia{a, {Streams: []probedStream{video}}}), "file 1 has 1 streams, not 2")
+}
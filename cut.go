@@ -0,0 +1,234 @@
+package ffmpeg_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CutOptions struct {
+	// Smart cuts exactly at start and end: the partial GOPs at both ends are
+	// re-encoded and joined with the stream copied GOPs between them.
+	// Otherwise the cut is widened to the surrounding keyframes.
+	Smart bool
+	// FfmpegPath is the ffmpeg binary, default "ffmpeg".
+	FfmpegPath string
+	// KwArgs are the output options of the re-encoded parts, e.g.
+	// {"crf": 18}. The codecs, pixel format, profile, level and time base
+	// of the input are used, and the parameter sets are repeated in band.
+	// Only H.264 and HEVC video can be cut smart.
+	KwArgs KwArgs
+}
+
+// smartCutEncoders are the encoders of the re-encoded parts by the probed
+// codec of the input.
+var smartCutEncoders = map[string]string{
+	"h264":   "libx264",
+	"hevc":   "libx265",
+	"aac":    "aac",
+	"mp3":    "libmp3lame",
+	"opus":   "libopus",
+	"vorbis": "libvorbis",
+	"ac3":    "ac3",
+	"flac":   "flac",
+}
+
+// CutLossless cuts start to end of input into fileName without re-encoding
+// the whole video. The keyframes of the first video stream are inspected
+// with ProbePackets: by default the cut is widened to the keyframe at or
+// before start and the keyframe at or after end, so that stream copy starts
+// with a decodable frame. It returns the interval of input written.
+func CutLossless(ctx context.Context, input string, start, end time.Duration, fileName string, opts ...CutOptions) (Interval, error) {
+	opt := CutOptions{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.FfmpegPath == "" {
+		opt.FfmpegPath = "ffmpeg"
+	}
+	if end <= start {
+		return Interval{}, errors.New("cut ends before it starts")
+	}
+	media, err := probeMedia(ctx, input)
+	if err != nil {
+		return Interval{}, err
+	}
+	if media.Duration > 0 && end > media.Duration {
+		end = media.Duration
+	}
+	keyframes, err := Keyframes(ctx, input, "v:0")
+	if err != nil {
+		return Interval{}, err
+	}
+	if len(keyframes) == 0 {
+		return Interval{}, fmt.Errorf("no keyframes in %s", input)
+	}
+	if !opt.Smart {
+		cut := snapToKeyframes(keyframes, start, end, media.Duration)
+		return cut, copyPart(ctx, input, cut, fileName, opt, KwArgs{"map": "0"})
+	}
+	return Interval{Start: start, End: end}, smartCut(ctx, input, media, keyframes, Interval{Start: start, End: end}, fileName, opt)
+}
+
+// snapToKeyframes widens start and end to the surrounding keyframes, end is
+// the end of the input if there is no keyframe after it.
+func snapToKeyframes(keyframes []time.Duration, start, end, duration time.Duration) Interval {
+	cut := Interval{Start: keyframes[0], End: duration}
+	for _, k := range keyframes {
+		if k <= start {
+			cut.Start = k
+		}
+	}
+	for i := len(keyframes) - 1; i >= 0 && keyframes[i] >= end; i-- {
+		cut.End = keyframes[i]
+	}
+	if cut.End == 0 {
+		cut.End = end
+	}
+	return cut
+}
+
+// smartCutParts splits cut into the partial GOP before the first keyframe in
+// cut, the GOPs to copy and the partial GOP after the last keyframe. Empty
+// parts have zero duration.
+func smartCutParts(keyframes []time.Duration, cut Interval) (head, middle, tail Interval) {
+	i := sort.Search(len(keyframes), func(i int) bool { return keyframes[i] >= cut.Start })
+	j := sort.Search(len(keyframes), func(i int) bool { return keyframes[i] > cut.End }) - 1
+	if i > j || i == len(keyframes) {
+		// no keyframe in the cut, everything is re-encoded
+		return cut, Interval{Start: cut.End, End: cut.End}, Interval{Start: cut.End, End: cut.End}
+	}
+	first, last := keyframes[i], keyframes[j]
+	return Interval{Start: cut.Start, End: first}, Interval{Start: first, End: last}, Interval{Start: last, End: cut.End}
+}
+
+func smartCut(ctx context.Context, input string, media probedMedia, keyframes []time.Duration, cut Interval, fileName string, opt CutOptions) error {
+	encode := KwArgs{}
+	for _, s := range media.Streams {
+		if s.CodecType != "video" && s.CodecType != "audio" {
+			continue
+		}
+		if _, ok := encode["c:"+s.CodecType[:1]]; ok {
+			continue
+		}
+		encoder, ok := smartCutEncoders[s.CodecName]
+		if !ok {
+			return fmt.Errorf("smart cut of %s not supported", s.CodecName)
+		}
+		encode["c:"+s.CodecType[:1]] = encoder
+		if s.CodecType == "video" {
+			video, err := smartCutVideoArgs(s, fileName)
+			if err != nil {
+				return err
+			}
+			encode = MergeKwArgs([]KwArgs{encode, video})
+		}
+	}
+	dir, err := ioutil.TempDir("", "ffmpeg_go_cut_")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	head, middle, tail := smartCutParts(keyframes, cut)
+	var parts []string
+	for i, part := range []Interval{head, middle, tail} {
+		if part.Duration() <= 0 {
+			continue
+		}
+		path := filepath.Join(dir, fmt.Sprintf("part%d%s", i, filepath.Ext(fileName)))
+		if i == 1 {
+			err = copyPart(ctx, input, part, path, opt, KwArgs{"map": smartCutMaps})
+		} else {
+			err = encodePart(ctx, input, part, path, opt, encode)
+		}
+		if err != nil {
+			return err
+		}
+		parts = append(parts, path)
+	}
+	// overwrite fileName like the stream copy of a cut that isn't smart
+	return concatDemuxer(ctx, parts, fileName, ConcatOptions{FfmpegPath: opt.FfmpegPath, OverWriteOutput: true})
+}
+
+// smartCutProfiles are the encoder profiles by codec and the profile
+// reported by ffprobe.
+var smartCutProfiles = map[string]map[string]string{
+	"h264": {
+		"Constrained Baseline":  "baseline",
+		"Baseline":              "baseline",
+		"Main":                  "main",
+		"High":                  "high",
+		"High 10":               "high10",
+		"High 4:2:2":            "high422",
+		"High 4:4:4 Predictive": "high444",
+	},
+	"hevc": {
+		"Main":               "main",
+		"Main 10":            "main10",
+		"Main Still Picture": "mainstillpicture",
+	},
+}
+
+// smartCutVideoArgs returns the options that encode video like s, so that
+// the re-encoded parts can be joined with the copied ones. The parameter
+// sets are repeated in band, as the joined file has the ones of the first
+// part only. It fails if the parameters of s can't be matched.
+func smartCutVideoArgs(s probedStream, fileName string) (KwArgs, error) {
+	profile, ok := smartCutProfiles[s.CodecName][s.Profile]
+	if !ok || s.Level <= 0 {
+		return nil, fmt.Errorf("smart cut of %s profile %q level %d not supported", s.CodecName, s.Profile, s.Level)
+	}
+	args := KwArgs{"profile:v": profile}
+	if s.PixFmt != "" {
+		args["pix_fmt"] = s.PixFmt
+	}
+	switch s.CodecName {
+	case "h264":
+		args["level"] = formatFloat(float64(s.Level) / 10)
+		args["x264-params"] = "repeat-headers=1"
+	case "hevc":
+		args["x265-params"] = fmt.Sprintf("level-idc=%s:repeat-headers=1", formatFloat(float64(s.Level)/30))
+	}
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".mp4", ".m4v", ".mov":
+		timescale := strings.TrimPrefix(s.TimeBase, "1/")
+		if _, err := strconv.Atoi(timescale); err != nil {
+			return nil, fmt.Errorf("smart cut of time base %q not supported", s.TimeBase)
+		}
+		args["video_track_timescale"] = timescale
+	}
+	return args, nil
+}
+
+// smartCutMaps are the streams of a smart cut, the parts must have the same
+// streams to be joined.
+var smartCutMaps = []string{"0:v:0", "0:a?"}
+
+func copyPart(ctx context.Context, input string, part Interval, fileName string, opt CutOptions, maps KwArgs) error {
+	in := Input(input, KwArgs{"ss": formatSeconds(part.Start)})
+	o := OutputContext(ctx, []*Stream{in}, fileName, maps, KwArgs{
+		"t":                 formatSeconds(part.Duration()),
+		"c":                 "copy",
+		"avoid_negative_ts": "make_zero",
+	}).OverWriteOutput()
+	o.FfmpegPath = opt.FfmpegPath
+	return runWithLogParser(o, nil)
+}
+
+func encodePart(ctx context.Context, input string, part Interval, fileName string, opt CutOptions, encode KwArgs) error {
+	in := Input(input, KwArgs{"ss": formatSeconds(part.Start)})
+	o := OutputContext(ctx, []*Stream{in}, fileName, opt.KwArgs, encode, KwArgs{
+		"t":   formatSeconds(part.Duration()),
+		"map": smartCutMaps,
+	}).OverWriteOutput()
+	o.FfmpegPath = opt.FfmpegPath
+	return runWithLogParser(o, nil)
+}
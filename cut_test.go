@@ -0,0 +1,35 @@
+package ffmpeg_go
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapToKeyframes(t *testing.T) {
+	keyframes := []time.Duration{0, 2 * time.Second, 4 * time.Second, 6 * time.Second}
+	assert.Equal(t, Interval{Start: 2 * time.Second, End: 6 * time.Second},
+		snapToKeyframes(keyframes, 3*time.Second, 5*time.Second, 7*time.Second))
+	assert.Equal(t, Interval{Start: 4 * time.Second, End: 6 * time.Second},
+		snapToKeyframes(keyframes, 4*time.Second, 6*time.Second, 7*time.Second))
+	assert.Equal(t, Interval{Start: 6 * time.Second, End: 7 * time.Second},
+		snapToKeyframes(keyframes, 6500*time.Millisecond, 7*time.Second, 7*time.Second))
+}
+
+func TestSmartCutParts(t *testing.T) {
+	keyframes := []time.Duration{0, 2 * time.Second, 4 * time.Second, 6 * time.Second}
+	head, middle, tail := smartCutParts(keyframes, Interval{Start: 1 * time.Second, End: 5 * time.Second})
+	assert.Equal(t, Interval{Start: 1 * time.Second, End: 2 * time.Second}, head)
+	assert.Equal(t, Interval{Start: 2 * time.Second, End: 4 * time.Second}, middle)
+	assert.Equal(t, Interval{Start: 4 * time.Second, End: 5 * time.Second}, tail)
+
+	head, middle, tail = smartCutParts(keyframes, Interval{Start: 2 * time.Second, End: 4 * time.Second})
+	assert.Equal(t, time.Duration(0), head.Duration())
+	assert.Equal(t, Interval{Start: 2 * time.Second, End: 4 * time.Second}, middle)
+	assert.Equal(t, time.Duration(0), tail.Duration())
+
+	head, middle, tail = smartCutParts(keyframes, Interval{Start: 2500 * time.Millisecond, End: 3 * time.Second})
+	assert.Equal(t, Interval{Start: 2500 * time.Millisecond, End: 3 * time.Second}, head)
+	assert.Equal(t, time.Duration(0), middle.Duration()+tail.Duration())
+}
@@ -0,0 +1,160 @@
+package ffmpeg_go
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EDLSource resolves the source of an EDL event from its reel name and the
+// clip name of the “* FROM CLIP NAME:“ comment, which may be empty.
+type EDLSource func(reel, clipName string) (*Stream, error)
+
+var (
+	edlEventRegexp = regexp.MustCompile(`^(\d+)\s+(\S+)\s+(\S+)\s+(C|D|W\d*|K\S*)\s+(?:(\d+)\s+)?` +
+		`(\d\d[:;]\d\d[:;]\d\d[:;]\d\d)\s+(\d\d[:;]\d\d[:;]\d\d[:;]\d\d)\s+` +
+		`(\d\d[:;]\d\d[:;]\d\d[:;]\d\d)\s+(\d\d[:;]\d\d[:;]\d\d[:;]\d\d)`)
+	edlSpeedRegexp = regexp.MustCompile(`^M2\s+(\S+)\s+(-?[\d.]+)\s+`)
+)
+
+type edlEvent struct {
+	number     string
+	reel       string
+	transition string
+	// frames is the transition duration
+	frames int
+	// in and out are the source, recIn and recOut the record timecodes
+	in, out       time.Duration
+	recIn, recOut time.Duration
+	clipName      string
+	speed         float64
+}
+
+// ParseEDL imports the video events of a CMX3600 edit decision list as a
+// Timeline. fps is the frame rate of the timecodes. Dissolves become "fade"
+// and wipes "wipeleft" transitions; M2 motion effects set the clip speed.
+//
+// The clips last as long as their record timecodes, the source out of an
+// event is its source in plus the record duration times the speed. Gaps in
+// the record timecodes become black and silent Gap clips. The outgoing side
+// of a transition plays from the source in of its zero length event for
+// the length of the transition, or from the end of the previous event if
+// there is none, so that the timeline is as long as the record timecodes.
+//
+// Only the first video track is imported, audio follows the video events.
+func ParseEDL(r io.Reader, fps float64, source EDLSource) (Timeline, error) {
+	if fps <= 0 {
+		return Timeline{}, fmt.Errorf("invalid edl frame rate %v", fps)
+	}
+	var events []*edlEvent
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if m := edlEventRegexp.FindStringSubmatch(line); m != nil {
+			if !strings.HasPrefix(m[3], "V") {
+				continue
+			}
+			e := &edlEvent{number: m[1], reel: m[2], transition: m[4]}
+			e.frames, _ = strconv.Atoi(m[5])
+			e.in = parseTimecode(m[6], fps)
+			e.out = parseTimecode(m[7], fps)
+			e.recIn = parseTimecode(m[8], fps)
+			e.recOut = parseTimecode(m[9], fps)
+			events = append(events, e)
+			continue
+		}
+		if len(events) == 0 {
+			continue
+		}
+		last := events[len(events)-1]
+		if v := strings.TrimPrefix(line, "* FROM CLIP NAME:"); v != line && last.clipName == "" {
+			last.clipName = strings.TrimSpace(v)
+		} else if m := edlSpeedRegexp.FindStringSubmatch(line); m != nil && m[1] == last.reel {
+			if speed, err := strconv.ParseFloat(m[2], 64); err == nil && speed > 0 {
+				last.speed = speed / fps
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Timeline{}, err
+	}
+
+	t := Timeline{FrameRate: formatFloat(fps)}
+	frame := time.Duration(float64(time.Second) / fps)
+	// prev is the event of the last clip, end the record out of the timeline
+	var prev, outgoing *edlEvent
+	var end time.Duration
+	for _, e := range events {
+		if e.recOut <= e.recIn {
+			// the outgoing side of a transition is logged as a zero length
+			// event
+			outgoing = e
+			continue
+		}
+		if gap := e.recIn - end; prev != nil && gap > 0 {
+			t.Clips = append(t.Clips, Clip{Gap: true, Out: gap})
+		}
+		if e.transition != "C" && len(t.Clips) > 0 {
+			td := time.Duration(math.Round(float64(e.frames) / fps * float64(time.Second)))
+			last := &t.Clips[len(t.Clips)-1]
+			if outgoing != nil && (outgoing.reel != prev.reel || absDuration(outgoing.in-last.Out) > frame/2) {
+				// the outgoing side doesn't continue the previous clip
+				s, err := source(outgoing.reel, outgoing.clipName)
+				if err != nil {
+					return Timeline{}, fmt.Errorf("edl event %s: %w", outgoing.number, err)
+				}
+				t.Clips = append(t.Clips, Clip{Source: s, In: outgoing.in, Out: outgoing.in + td})
+				last = &t.Clips[len(t.Clips)-1]
+			} else {
+				last.Out += scaleDuration(td, last.Speed)
+			}
+			last.Transition = "fade"
+			if strings.HasPrefix(e.transition, "W") {
+				last.Transition = "wipeleft"
+			}
+			last.TransitionDuration = td
+		}
+		outgoing = nil
+		s, err := source(e.reel, e.clipName)
+		if err != nil {
+			return Timeline{}, fmt.Errorf("edl event %s: %w", e.number, err)
+		}
+		out := e.in + scaleDuration(e.recOut-e.recIn, e.speed)
+		t.Clips = append(t.Clips, Clip{Source: s, In: e.in, Out: out, Speed: e.speed})
+		prev, end = e, e.recOut
+	}
+	return t, nil
+}
+
+// scaleDuration returns the source duration played in d at speed, 0 is
+// normal speed.
+func scaleDuration(d time.Duration, speed float64) time.Duration {
+	if speed <= 0 {
+		return d
+	}
+	return time.Duration(math.Round(float64(d) * speed))
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// parseTimecode parses a “HH:MM:SS:FF“ timecode, drop frame timecodes
+// (“HH:MM:SS;FF“) are treated as non drop frame.
+func parseTimecode(tc string, fps float64) time.Duration {
+	l := strings.FieldsFunc(tc, func(r rune) bool { return r == ':' || r == ';' })
+	var v [4]int
+	for i := range l {
+		v[i], _ = strconv.Atoi(l[i])
+	}
+	seconds := float64(v[0]*3600+v[1]*60+v[2]) + float64(v[3])/fps
+	return secondsToDuration(seconds)
+}
@@ -0,0 +1,45 @@
+package ffmpeg_go
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+)
+
+// CommandError is the error of a failed ffmpeg or ffprobe process. It
+// unwraps to Err, which is the *exec.ExitError if the process exited, so
+// errors.As finds the exit error as before.
+type CommandError struct {
+	// Path and Args are the failed command.
+	Path string
+	Args []string
+	// Stderr is what the command wrote to stderr, or its last lines for
+	// analysis runs. It is empty if stderr wasn't captured.
+	Stderr string
+	Err    error
+}
+
+func newCommandError(cmd *exec.Cmd, err error) *CommandError {
+	return &CommandError{Path: cmd.Path, Args: cmd.Args, Err: err}
+}
+
+func (e *CommandError) Error() string {
+	if e.Stderr == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("[%s] %s", e.Stderr, e.Err)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code of the command, or -1 if it didn't start or
+// was killed by a signal.
+func (e *CommandError) ExitCode() int {
+	var exitErr *exec.ExitError
+	if errors.As(e.Err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
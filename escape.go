@@ -0,0 +1,93 @@
+package ffmpeg_go
+
+import (
+	"fmt"
+	"strings"
+)
+
+// A filter in a graph description is unescaped twice by ffmpeg: the graph
+// parser reads the filter name and its arguments as tokens terminated by
+// one of "[],;", then the filter splits the arguments into options at ":".
+// Both levels drop unescaped leading and trailing whitespace and treat "\"
+// and "'" as escape and quote characters, so every value is escaped for the
+// option level first and the joined arguments for the graph level.
+const (
+	optionSpecialChars = `\':`
+	graphSpecialChars  = `\'[],;`
+	whitespaceChars    = " \n\t\r"
+)
+
+// escapeToken escapes the special characters of s and its leading and
+// trailing whitespace with a backslash.
+func escapeToken(s, special string) string {
+	start := len(s) - len(strings.TrimLeft(s, whitespaceChars))
+	end := len(strings.TrimRight(s, whitespaceChars))
+	b := strings.Builder{}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if strings.IndexByte(special, c) >= 0 || i < start || i >= end {
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// escapeFilterName escapes the name of a filter, which is terminated by "="
+// too.
+func escapeFilterName(name string) string {
+	return escapeToken(name, graphSpecialChars+"=")
+}
+
+// escapeFilterArg escapes a positional argument, "=" is escaped so that the
+// argument isn't taken for an option name.
+func escapeFilterArg(arg string) string {
+	return escapeToken(arg, optionSpecialChars+"=")
+}
+
+// escapeOptionValue escapes the value of a named option.
+func escapeOptionValue(v string) string {
+	return escapeToken(v, optionSpecialChars)
+}
+
+// escapeFilterArgs escapes the joined arguments of a filter for the graph.
+func escapeFilterArgs(args string) string {
+	return escapeToken(args, graphSpecialChars)
+}
+
+// isOptionName reports whether name can be parsed as an option name, which
+// can't be escaped.
+func isOptionName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, c := range name {
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.ContainsRune("-_./", c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterString returns the escaped filter description "name=arg:key=value".
+func filterString(name string, args []string, kwargs KwArgs) string {
+	var params []string
+	for _, a := range args {
+		params = append(params, escapeFilterArg(a))
+	}
+	for _, k := range kwargs.SortedKeys() {
+		if !isOptionName(k) {
+			panic(fmt.Sprintf("invalid option name %q of filter %s", k, name))
+		}
+		if v := getString(kwargs[k]); v != "" {
+			params = append(params, k+"="+escapeOptionValue(v))
+		} else {
+			params = append(params, k)
+		}
+	}
+	ret := escapeFilterName(name)
+	if len(params) > 0 {
+		ret += "=" + escapeFilterArgs(strings.Join(params, ":"))
+	}
+	return ret
+}
@@ -0,0 +1,32 @@
+//go:build go1.18
+// +build go1.18
+
+package ffmpeg_go
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzFilterString(f *testing.F) {
+	for _, seed := range []string{"a:b", "a=b", `C:\it's.srt`, "[x],y;z", " ", `\`, "%{pts}"} {
+		f.Add("drawtext", seed, seed)
+	}
+	f.Fuzz(func(t *testing.T, name, arg, value string) {
+		// ffmpeg reads C strings and drops an empty last argument
+		if name == "" || arg == "" || value == "" || strings.ContainsRune(name+arg+value, 0) {
+			t.Skip()
+		}
+		desc := filterString(name, []string{arg}, KwArgs{"v": value})
+		gotName, args, kwargs, err := parseFilter(desc)
+		if err != nil {
+			t.Fatalf("%q: %v", desc, err)
+		}
+		if gotName != name {
+			t.Fatalf("%q: name %q, want %q", desc, gotName, name)
+		}
+		if len(args) != 1 || args[0] != arg || kwargs["v"] != value {
+			t.Fatalf("%q: parsed %q %q, want %q %q", desc, args, kwargs, arg, value)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package ffmpeg_go
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// getToken reads a token terminated by one of term and unescapes it like
+// av_get_token of ffmpeg.
+func getToken(buf, term string) (token, rest string) {
+	buf = strings.TrimLeft(buf, whitespaceChars)
+	var out []byte
+	end, i := 0, 0
+	for i < len(buf) && strings.IndexByte(term, buf[i]) < 0 {
+		c := buf[i]
+		i++
+		switch {
+		case c == '\\' && i < len(buf):
+			out = append(out, buf[i])
+			i++
+			end = len(out)
+		case c == '\'':
+			for i < len(buf) && buf[i] != '\'' {
+				out = append(out, buf[i])
+				i++
+			}
+			if i < len(buf) {
+				i++
+				end = len(out)
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	for len(out) > end && strings.IndexByte(whitespaceChars, out[len(out)-1]) >= 0 {
+		out = out[:len(out)-1]
+	}
+	return string(out), buf[i:]
+}
+
+// optionKey reads an option name followed by "=".
+func optionKey(opts string) (key, rest string, ok bool) {
+	opts = strings.TrimLeft(opts, whitespaceChars)
+	i := 0
+	for i < len(opts) && isOptionName(opts[i:i+1]) {
+		i++
+	}
+	key, rest = opts[:i], strings.TrimLeft(opts[i:], whitespaceChars)
+	if key == "" || !strings.HasPrefix(rest, "=") {
+		return "", "", false
+	}
+	return key, rest[1:], true
+}
+
+// parseFilter parses a filter description the way the filter graph parser
+// and the option parser of ffmpeg do.
+func parseFilter(desc string) (name string, args []string, kwargs map[string]string, err error) {
+	name, rest := getToken(desc, "=,;[")
+	if rest == "" {
+		return name, nil, nil, nil
+	}
+	if rest[0] != '=' {
+		return "", nil, nil, errors.New("unexpected " + rest)
+	}
+	opts, rest := getToken(rest[1:], "[],;")
+	if rest != "" {
+		return "", nil, nil, errors.New("unexpected " + rest)
+	}
+	kwargs = map[string]string{}
+	for opts != "" {
+		var v string
+		if key, after, ok := optionKey(opts); ok {
+			v, opts = getToken(after, ":")
+			kwargs[key] = v
+		} else {
+			if len(kwargs) > 0 {
+				return "", nil, nil, errors.New("positional argument after option")
+			}
+			v, opts = getToken(opts, ":")
+			args = append(args, v)
+		}
+		opts = strings.TrimPrefix(opts, ":")
+	}
+	return name, args, kwargs, nil
+}
+
+func TestEscapeToken(t *testing.T) {
+	assert.Equal(t, `a\:b`, escapeToken("a:b", optionSpecialChars))
+	assert.Equal(t, `\\\'`, escapeToken(`\'`, optionSpecialChars))
+	assert.Equal(t, `\ a b\ \ `, escapeToken(" a b  ", optionSpecialChars))
+	assert.Equal(t, `\[in\]\,\;`, escapeToken("[in],;", graphSpecialChars))
+	assert.Equal(t, "", escapeToken("", graphSpecialChars))
+}
+
+func TestFilterString(t *testing.T) {
+	assert.Equal(t, `scale=iw/2:-2:flags=lanczos`, filterString("scale", []string{"iw/2", "-2"}, KwArgs{"flags": "lanczos"}))
+	assert.Equal(t, `select=gt(scene\,0.4)`, filterString("select", []string{"gt(scene,0.4)"}, nil))
+	assert.Equal(t, `subtitles=C\\:/subs/it\\\'s.srt`, filterString("subtitles", []string{`C:/subs/it's.srt`}, nil))
+	assert.Equal(t, `hflip`, filterString("hflip", nil, nil))
+	assert.Panics(t, func() { filterString("scale", nil, KwArgs{"w:h": 1}) })
+}
+
+func TestFilterStringRoundTrip(t *testing.T) {
+	values := []string{
+		"plain", "a:b", "a=b", `C:\path\to it's.srt`, "[x],y;z", "100%", " padded ", "'", `\`, "tab\t", "a\\:b", "ünï",
+	}
+	for _, v := range values {
+		desc := filterString("drawtext", []string{v}, KwArgs{"text": v, "x": "(w-tw)/2"})
+		name, args, kwargs, err := parseFilter(desc)
+		assert.NoError(t, err, desc)
+		assert.Equal(t, "drawtext", name)
+		assert.Equal(t, []string{v}, args, desc)
+		assert.Equal(t, map[string]string{"text": v, "x": "(w-tw)/2"}, kwargs, desc)
+	}
+}
@@ -84,12 +84,12 @@ func TestExampleScaleVideo(t *testing.T) {
 
 func TestExampleAddWatermark(t *testing.T) {
 	// show watermark with size 64:-1 in the top left corner after seconds 1
-	overlay := ffmpeg.Input("./sample_data/overlay.png").Filter("scale", ffmpeg.Args{"64:-1"})
+	overlay := ffmpeg.Input("./sample_data/overlay.png").Filter("scale", ffmpeg.Args{"64", "-1"})
 	err := ffmpeg.Filter(
 		[]*ffmpeg.Stream{
 			ffmpeg.Input("./sample_data/in1.mp4"),
 			overlay,
-		}, "overlay", ffmpeg.Args{"10:10"}, ffmpeg.KwArgs{"enable": "gte(t,1)"}).
+		}, "overlay", ffmpeg.Args{"10", "10"}, ffmpeg.KwArgs{"enable": "gte(t,1)"}).
 		Output("./sample_data/out1.mp4").OverWriteOutput().ErrorToStdOut().Run()
 	assert.Nil(t, err)
 }
@@ -103,9 +103,9 @@ func TestExampleCutVideoForGif(t *testing.T) {
 
 func TestExampleMultipleOutput(t *testing.T) {
 	input := ffmpeg.Input("./sample_data/in1.mp4").Split()
-	out1 := input.Get("0").Filter("scale", ffmpeg.Args{"1920:-1"}).
+	out1 := input.Get("0").Filter("scale", ffmpeg.Args{"1920", "-1"}).
 		Output("./sample_data/1920.mp4", ffmpeg.KwArgs{"b:v": "5000k"})
-	out2 := input.Get("1").Filter("scale", ffmpeg.Args{"1280:-1"}).
+	out2 := input.Get("1").Filter("scale", ffmpeg.Args{"1280", "-1"}).
 		Output("./sample_data/1280.mp4", ffmpeg.KwArgs{"b:v": "2800k"})
 
 	err := ffmpeg.MergeOutputs(out1, out2).OverWriteOutput().ErrorToStdOut().Run()
@@ -3,6 +3,7 @@ package ffmpeg_go
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"os"
@@ -20,6 +21,12 @@ import (
 //
 // To tell ffmpeg to read from stdin, use “pipe:“ as the filename.
 //
+// Urls with a registered source scheme (e.g. “s3://bucket/key“) are read in
+// Go and streamed to ffmpeg through stdin. Set “seekable=true“ to download
+// them to a temp file first when the demuxer needs to seek, e.g. mp4 with the
+// moov atom at the end. See RegisterSource. Sources on extra file
+// descriptors and seekable ones are bound by Run, Compile panics for them.
+//
 // Official documentation: `Main options <https://ffmpeg.org/ffmpeg.html#Main-options>`__
 func Input(filename string, kwargs ...KwArgs) *Stream {
 	args := MergeKwArgs(kwargs)
@@ -30,7 +37,10 @@ func Input(filename string, kwargs ...KwArgs) *Stream {
 		}
 		args["format"] = fmt
 	}
-	return NewInputNode("input", nil, args).Stream("", "")
+	source := newRemoteSource(filename, args)
+	node := NewInputNode("input", nil, args)
+	node.source = source
+	return node.Stream("", "")
 }
 
 // Add extra global command-line argument(s), e.g. “-progress“.
@@ -53,7 +63,11 @@ func (s *Stream) OverwriteOutput(stream *Stream) *Stream {
 
 // Include all given outputs in one ffmpeg command line
 func MergeOutputs(streams ...*Stream) *Stream {
-	return NewMergeOutputsNode("merge_output", streams).Stream("", "")
+	o := NewMergeOutputsNode("merge_output", streams).Stream("", "")
+	for _, s := range streams {
+		o.Context = withRunHook(o.Context, getRunHooks(s.Context)...)
+	}
+	return o
 }
 
 // Output file URL
@@ -129,7 +143,6 @@ func (s *Stream) Output(fileName string, kwargs ...KwArgs) *Stream {
 }
 
 func (s *Stream) outputS3Stream(fileName string, kwargs ...KwArgs) *Stream {
-	r, w := io.Pipe()
 	fileL := strings.SplitN(strings.TrimPrefix(fileName, "s3://"), "/", 2)
 	if len(fileL) != 2 {
 		log.Panic("s3 file format not valid")
@@ -137,30 +150,44 @@ func (s *Stream) outputS3Stream(fileName string, kwargs ...KwArgs) *Stream {
 	args := MergeKwArgs(kwargs)
 	awsConfig := args.PopDefault("aws_config", &aws.Config{}).(*aws.Config)
 	bucket, key := fileL[0], fileL[1]
-	o := Output([]*Stream{s}, "pipe:", args).
-		WithOutput(w, os.Stdout)
-	done := make(chan struct{})
-	runHook := RunHook{
-		f: func() {
-			defer func() {
-				done <- struct{}{}
-			}()
+	return s.outputSinkStream(func(r io.Reader) error {
+		sess, err := session.NewSession(awsConfig)
+		if err != nil {
+			return err
+		}
+		// a failed multipart upload is aborted instead of leaving parts behind
+		uploader := s3manager.NewUploader(sess, func(u *s3manager.Uploader) {
+			u.LeavePartsOnError = false
+		})
+		_, err = uploader.Upload(&s3manager.UploadInput{
+			Bucket: &bucket,
+			Key:    &key,
+			Body:   r,
+		})
+		if err != nil {
+			return fmt.Errorf("upload s3://%s/%s fail: %w", bucket, key, err)
+		}
+		return nil
+	}, args)
+}
 
-			sess, err := session.NewSession(awsConfig)
-			uploader := s3manager.NewUploader(sess)
-			_, err = uploader.Upload(&s3manager.UploadInput{
-				Bucket: &bucket,
-				Key:    &key,
-				Body:   r,
-			})
-			//fmt.Println(ioutil.ReadAll(r))
-			if err != nil {
-				log.Println("upload fail", err)
-			}
+// outputSinkStream outputs to ffmpeg's stdout and streams it into sink while
+// ffmpeg is running. If ffmpeg fails the sink reads the same error instead of
+// EOF, so it can discard what it has written so far.
+func (s *Stream) outputSinkStream(sink func(r io.Reader) error, kwargs KwArgs) *Stream {
+	r, w := io.Pipe()
+	// the sink is bound to the output node, so it stays bound in MergeOutputs
+	o := OutputContext(s.Context, []*Stream{s}, "pipe:", kwargs).
+		WithErrorOutput(os.Stdout)
+	o.Node.pipe = &goPipe{writer: w, stdout: true}
+	o.Context = withRunHook(o.Context, &RunHook{
+		f: func() error {
+			err := sink(r)
+			// unblock ffmpeg if the sink stopped reading early
+			_ = r.CloseWithError(err)
+			return err
 		},
-		done:   done,
 		closer: w,
-	}
-	o.Context = context.WithValue(o.Context, "run_hook", &runHook)
+	})
 	return o
 }
@@ -1,9 +1,15 @@
 package ffmpeg_go
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
 	"os/exec"
+	"path/filepath"
 	"syscall"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -27,3 +33,90 @@ func TestGlobalCommandOptions(t *testing.T) {
 	assert.Equal(t, 0, cmd.SysProcAttr.Pgid)
 	assert.True(t, cmd.SysProcAttr.Setpgid)
 }
+
+// fakeFfmpeg writes a shell script standing in for the ffmpeg binary.
+func fakeFfmpeg(t *testing.T, script string) string {
+	path := filepath.Join(t.TempDir(), "ffmpeg")
+	err := ioutil.WriteFile(path, []byte("#!/bin/sh\n"+script+"\n"), 0755)
+	assert.Nil(t, err)
+	return path
+}
+
+// fakeFfmpegArgs returns the arguments saved by a fake ffmpeg script with
+// `echo "$@" > "$(dirname "$0")/args"`.
+func fakeFfmpegArgs(t *testing.T, ffmpeg string) string {
+	data, err := ioutil.ReadFile(filepath.Join(filepath.Dir(ffmpeg), "args"))
+	assert.Nil(t, err)
+	return string(data)
+}
+
+func TestRunSinkErrorKillsFfmpeg(t *testing.T) {
+	sinkErr := errors.New("sink fail")
+	out := Input("dummy.mp4").outputSinkStream(func(r io.Reader) error {
+		_, _ = r.Read(make([]byte, 16))
+		return sinkErr
+	}, KwArgs{"format": "mpegts"})
+	start := time.Now()
+	err := out.SetFfmpegPath(fakeFfmpeg(t, "echo data; exec sleep 10")).Run()
+	assert.True(t, errors.Is(err, sinkErr))
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestRunFfmpegErrorReachesSink(t *testing.T) {
+	var sinkErr error
+	out := Input("dummy.mp4").outputSinkStream(func(r io.Reader) error {
+		_, sinkErr = ioutil.ReadAll(r)
+		return sinkErr
+	}, KwArgs{"format": "mpegts"})
+	err := out.SetFfmpegPath(fakeFfmpeg(t, "echo partial; exit 1")).Run()
+	var exitErr *exec.ExitError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.True(t, errors.As(sinkErr, &exitErr))
+}
+
+func TestRunSinkSuccess(t *testing.T) {
+	buf := bytes.NewBuffer(nil)
+	out := Input("dummy.mp4").outputSinkStream(func(r io.Reader) error {
+		_, err := io.Copy(buf, r)
+		return err
+	}, KwArgs{"format": "mpegts"})
+	err := out.SetFfmpegPath(fakeFfmpeg(t, "echo data")).Run()
+	assert.Nil(t, err)
+	assert.Equal(t, "data\n", buf.String())
+}
+
+func TestRunCommandError(t *testing.T) {
+	ffmpeg := fakeFfmpeg(t, "exit 3")
+	err := Input("in.mp4").Output("out.mp4").SetFfmpegPath(ffmpeg).Run()
+	var cmdErr *CommandError
+	assert.True(t, errors.As(err, &cmdErr))
+	assert.Equal(t, 3, cmdErr.ExitCode())
+	assert.Equal(t, []string{ffmpeg, "-i", "in.mp4", "out.mp4"}, cmdErr.Args)
+	assert.EqualError(t, err, "exit status 3")
+	// callers of the *exec.ExitError of older versions still find it
+	var exitErr *exec.ExitError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 3, exitErr.ExitCode())
+}
+
+func TestRunMergedSink(t *testing.T) {
+	in := Input("dummy.mp4")
+	var first, second bytes.Buffer
+	sink := func(buf *bytes.Buffer) func(r io.Reader) error {
+		return func(r io.Reader) error {
+			_, err := io.Copy(buf, r)
+			return err
+		}
+	}
+	out := MergeOutputs(
+		in.outputSinkStream(sink(&first), KwArgs{"format": "mpegts"}),
+		in.Output("out.mp4"),
+		in.outputSinkStream(sink(&second), KwArgs{"format": "mpegts"}),
+	)
+	ffmpeg := fakeFfmpeg(t, `echo "$@" > "$(dirname "$0")/args"; echo data; echo more >&3`)
+	err := out.SetFfmpegPath(ffmpeg).Run()
+	assert.Nil(t, err)
+	assert.Equal(t, "data\n", first.String())
+	assert.Equal(t, "more\n", second.String())
+	assert.Equal(t, "-i dummy.mp4 -f mpegts pipe: out.mp4 -f mpegts pipe:3\n", fakeFfmpegArgs(t, ffmpeg))
+}
@@ -2,6 +2,7 @@ package ffmpeg_go
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -377,3 +378,17 @@ func printGraph(s *Stream) {
 //	err := out.Run()
 //	assert.Nil(t, err)
 //}
+
+func TestJoinErrors(t *testing.T) {
+	sinkErr := errors.New("sink fail")
+	cmdErr := &CommandError{Err: errors.New("exit status 1")}
+	err := joinErrors(nil, cmdErr, fmt.Errorf("upload: %w", sinkErr))
+	assert.EqualError(t, err, "exit status 1; upload: sink fail")
+	assert.True(t, err.(multiError).Is(sinkErr))
+	var target *CommandError
+	assert.True(t, err.(multiError).As(&target))
+	assert.Equal(t, cmdErr, target)
+	assert.False(t, err.(multiError).Is(errors.New("other")))
+	assert.Nil(t, joinErrors(nil, nil))
+	assert.Equal(t, sinkErr, joinErrors(nil, sinkErr))
+}
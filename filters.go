@@ -86,7 +86,7 @@ func (s *Stream) Drawtext(text string, x, y int, escape bool, kwargs ...KwArgs)
 	AssertType(s.Type, "FilterableStream", "drawtext")
 	args := MergeKwArgs(kwargs)
 	if escape && text != "" {
-		text = fmt.Sprintf("%q", text)
+		text = escapeDrawtext(text)
 	}
 	if text != "" {
 		args["text"] = text
@@ -0,0 +1,37 @@
+package ffmpeg_go
+
+import (
+	"bytes"
+	"context"
+	"time"
+)
+
+// ReadFrameAsJpeg returns the frame at given time as jpeg. kwargs are passed
+// to the output, e.g. {"vf": "scale=320:-1"}.
+//
+// If s is an input the “ss“ input option is used to seek, otherwise the
+// frames before are decoded and dropped. An input of a Go reader or a
+// streamed source is consumed by the call.
+func (s *Stream) ReadFrameAsJpeg(ctx context.Context, at time.Duration, kwargs ...KwArgs) ([]byte, error) {
+	AssertType(s.Type, "FilterableStream", "read frame")
+	in, outArgs := s, KwArgs{}
+	if s.Node.nodeType == "InputNode" {
+		args := s.Node.kwargs.Copy()
+		args["ss"] = formatSeconds(at)
+		n := NewInputNode("input", nil, args)
+		n.source, n.pipe = s.Node.source, s.Node.pipe
+		in = n.Stream(s.Label, s.Selector)
+		in.FfmpegPath = s.FfmpegPath
+	} else {
+		outArgs["ss"] = formatSeconds(at)
+	}
+	buf := bytes.NewBuffer(nil)
+	o := OutputContext(ctx, []*Stream{in}, "pipe:", MergeKwArgs(kwargs), outArgs,
+		KwArgs{"vframes": 1, "format": "image2", "vcodec": "mjpeg"})
+	o.FfmpegPath = s.FfmpegPath
+	err := runWithLogParser(o.WithOutput(buf), nil)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,120 @@
+package ffmpeg_go
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+)
+
+type GIFOptions struct {
+	// FPS is the frame rate of the gif, 0 keeps the input frame rate.
+	FPS float64
+	// Width is the width of the gif, the height keeps the aspect ratio. 0
+	// keeps the input width.
+	Width int
+	// Dither is the dithering of paletteuse, e.g. "bayer", "sierra2_4a" or
+	// "none", empty uses the filter's default.
+	Dither string
+	// MaxColors is the palette size of palettegen, at most 256.
+	MaxColors int
+	// Loop is the loop count of the gif muxer, 0 loops forever and -1 plays
+	// the gif once.
+	Loop int
+	// MaxBytes is the size budget of WriteGIF, 0 for no budget.
+	MaxBytes int64
+	// KwArgs are passed to the output verbatim.
+	KwArgs KwArgs
+}
+
+// ToGIF outputs s as gif with a palette generated from s itself: the video
+// is split into palettegen and paletteuse, so that a single ffmpeg run reads
+// the input only once.
+func (s *Stream) ToGIF(fileName string, opts GIFOptions) *Stream {
+	AssertType(s.Type, "FilterableStream", "gif")
+	v := videoOf(s)
+	if opts.FPS > 0 {
+		v = v.Filter("fps", Args{formatFloat(opts.FPS)})
+	}
+	if opts.Width > 0 {
+		v = v.Filter("scale", Args{fmt.Sprint(opts.Width), "-1"}, KwArgs{"flags": "lanczos"})
+	}
+	split := v.Split()
+	paletteArgs := KwArgs{}
+	if opts.MaxColors > 0 {
+		paletteArgs["max_colors"] = opts.MaxColors
+	}
+	palette := split.Get("0").Filter("palettegen", nil, paletteArgs)
+	useArgs := KwArgs{}
+	if opts.Dither != "" {
+		useArgs["dither"] = opts.Dither
+	}
+	gif := Filter([]*Stream{split.Get("1"), palette}, "paletteuse", nil, useArgs)
+	gif.Context = s.Context
+	o := gif.Output(fileName, opts.KwArgs, KwArgs{"loop": opts.Loop})
+	o.FfmpegPath = s.FfmpegPath
+	return o
+}
+
+// gifMinFPS and gifMinWidth are the lowest values WriteGIF reduces to.
+const (
+	gifMinFPS   = 4
+	gifMinWidth = 64
+	// gifMaxTries limits the runs of WriteGIF to meet the budget.
+	gifMaxTries = 8
+)
+
+// WriteGIF runs ToGIF. If opts.MaxBytes is set, the gif is written again
+// with lower frame rate and width until it fits the budget, starting from
+// 15 fps and 480 pixels if FPS or Width aren't set. It returns the options
+// of the written gif.
+func (s *Stream) WriteGIF(ctx context.Context, fileName string, opts GIFOptions) (GIFOptions, error) {
+	if opts.MaxBytes > 0 {
+		if opts.FPS <= 0 {
+			opts.FPS = 15
+		}
+		if opts.Width <= 0 {
+			opts.Width = 480
+		}
+	}
+	// the output inherits ctx from its input, setting it on the output would
+	// drop the options OverWriteOutput stores in the context
+	input := *s
+	input.Context = ctx
+	for try := 0; ; try++ {
+		o := input.ToGIF(fileName, opts).OverWriteOutput()
+		if err := runWithLogParser(o, nil); err != nil {
+			return opts, err
+		}
+		if opts.MaxBytes <= 0 {
+			return opts, nil
+		}
+		info, err := os.Stat(fileName)
+		if err != nil {
+			return opts, err
+		}
+		if info.Size() <= opts.MaxBytes {
+			return opts, nil
+		}
+		next, ok := shrinkGIF(opts, float64(opts.MaxBytes)/float64(info.Size()))
+		if !ok || try+1 >= gifMaxTries {
+			return opts, fmt.Errorf("gif of %d bytes exceeds the budget of %d bytes", info.Size(), opts.MaxBytes)
+		}
+		opts = next
+	}
+}
+
+// shrinkGIF lowers fps and width of a gif whose budget is ratio of its size.
+// The size is roughly proportional to fps times width times height, so all
+// three are reduced by the cube root of ratio.
+func shrinkGIF(opts GIFOptions, ratio float64) (GIFOptions, bool) {
+	// aim a bit lower, as the size doesn't scale exactly
+	factor := math.Cbrt(ratio * 0.9)
+	fps := math.Max(gifMinFPS, math.Floor(opts.FPS*factor))
+	width := int(math.Max(gifMinWidth, float64(opts.Width)*factor))
+	if fps >= opts.FPS && width >= opts.Width {
+		return opts, false
+	}
+	opts.FPS, opts.Width = fps, width
+	return opts, true
+}
@@ -0,0 +1,31 @@
+package ffmpeg_go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToGIF(t *testing.T) {
+	args := Input("in.mp4", KwArgs{"ss": 1, "t": 3}).
+		ToGIF("out.gif", GIFOptions{FPS: 10, Width: 320, Dither: "bayer", MaxColors: 128}).GetArgs()
+	assert.Equal(t, []string{
+		"-ss", "1", "-t", "3", "-i", "in.mp4", "-filter_complex",
+		"[0:v]fps=10[s0];[s0]scale=320:-1:flags=lanczos[s1];[s1]split=2[s2][s3];" +
+			"[s2]palettegen=max_colors=128[s4];[s3][s4]paletteuse=dither=bayer[s5]",
+		"-map", "[s5]", "-loop", "0", "out.gif",
+	}, args)
+}
+
+func TestShrinkGIF(t *testing.T) {
+	opts, ok := shrinkGIF(GIFOptions{FPS: 15, Width: 480}, 0.5)
+	assert.True(t, ok)
+	assert.Equal(t, GIFOptions{FPS: 11, Width: 367}, opts)
+
+	opts, ok = shrinkGIF(GIFOptions{FPS: 15, Width: 480}, 0.001)
+	assert.True(t, ok)
+	assert.Equal(t, GIFOptions{FPS: gifMinFPS, Width: gifMinWidth}, opts)
+
+	_, ok = shrinkGIF(opts, 0.5)
+	assert.False(t, ok)
+}
@@ -0,0 +1,142 @@
+package ffmpeg_go
+
+import (
+	"fmt"
+	"strings"
+)
+
+type LayoutOptions struct {
+	// MixAudio mixes the audio of the streams with amix, the streams must
+	// then be inputs without selector.
+	MixAudio bool
+	// Background is the color of padding and empty cells, default black.
+	Background string
+}
+
+func layoutOptions(opts []LayoutOptions) LayoutOptions {
+	opt := LayoutOptions{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Background == "" {
+		opt.Background = "black"
+	}
+	return opt
+}
+
+// mixAudio mixes the audio of streams with amix, or returns nil if not
+// requested.
+func mixAudio(streams []*Stream, opt LayoutOptions) *Stream {
+	if !opt.MixAudio {
+		return nil
+	}
+	var audios []*Stream
+	for i, s := range streams {
+		if _, withAudio := mediaOf(s); !withAudio {
+			panic(fmt.Sprintf("stream %d has no audio to mix", i))
+		}
+		audios = append(audios, audioOf(s))
+	}
+	if len(audios) == 1 {
+		return audios[0]
+	}
+	return AMix(audios)
+}
+
+// fitCell scales s to fit into w x h keeping the aspect ratio, and pads it
+// to the exact size.
+func fitCell(s *Stream, w, h int, background string) *Stream {
+	return videoOf(s).
+		Filter("scale", Args{fmt.Sprint(w), fmt.Sprint(h)}, KwArgs{"force_original_aspect_ratio": "decrease"}).
+		Filter("pad", Args{fmt.Sprint(w), fmt.Sprint(h), "(ow-iw)/2", "(oh-ih)/2"}, KwArgs{"color": background}).
+		Filter("setsar", Args{"1"})
+}
+
+// Grid puts streams into a grid of cols columns with xstack, row by row.
+// Each stream is scaled to fit a cellW x cellH cell, empty cells of the last
+// row are filled with the background.
+func Grid(streams []*Stream, cols, cellW, cellH int, opts ...LayoutOptions) (video, audio *Stream) {
+	if len(streams) == 0 || cols <= 0 || cellW <= 0 || cellH <= 0 {
+		panic("grid needs streams and a positive number of columns and cell size")
+	}
+	opt := layoutOptions(opts)
+	var layout []string
+	var inputs []*Stream
+	for i, s := range streams {
+		inputs = append(inputs, fitCell(s, cellW, cellH, opt.Background))
+		layout = append(layout, fmt.Sprintf("%d_%d", i%cols*cellW, i/cols*cellH))
+	}
+	if len(inputs) == 1 {
+		return inputs[0], mixAudio(streams, opt)
+	}
+	args := KwArgs{"inputs": len(inputs), "layout": strings.Join(layout, "|")}
+	if len(inputs) > cols && len(inputs)%cols != 0 {
+		args["fill"] = opt.Background
+	}
+	return Filter(inputs, "xstack", nil, args), mixAudio(streams, opt)
+}
+
+// SideBySide puts streams next to each other with hstack, each scaled to
+// height keeping its aspect ratio.
+func SideBySide(streams []*Stream, height int, opts ...LayoutOptions) (video, audio *Stream) {
+	return stackStreams(streams, "hstack", "-2", fmt.Sprint(height), opts)
+}
+
+// Stack puts streams on top of each other with vstack, each scaled to width
+// keeping its aspect ratio.
+func Stack(streams []*Stream, width int, opts ...LayoutOptions) (video, audio *Stream) {
+	return stackStreams(streams, "vstack", fmt.Sprint(width), "-2", opts)
+}
+
+func stackStreams(streams []*Stream, filter, w, h string, opts []LayoutOptions) (video, audio *Stream) {
+	if len(streams) < 2 {
+		panic(fmt.Sprintf("%s needs at least two streams", filter))
+	}
+	opt := layoutOptions(opts)
+	var inputs []*Stream
+	for _, s := range streams {
+		inputs = append(inputs, videoOf(s).Filter("scale", Args{w, h}).Filter("setsar", Args{"1"}))
+	}
+	return Filter(inputs, filter, nil, KwArgs{"inputs": len(inputs)}), mixAudio(streams, opt)
+}
+
+// Corner is the position of the inset of PictureInPicture.
+type Corner int
+
+const (
+	TopLeft Corner = iota
+	TopRight
+	BottomLeft
+	BottomRight
+)
+
+// PictureInPicture overlays inset on main in corner, margin pixels from the
+// edges. The inset is scaled to scale times the width of main with
+// scale2ref, keeping its aspect ratio.
+func PictureInPicture(main, inset *Stream, corner Corner, scale float64, margin int, opts ...LayoutOptions) (video, audio *Stream) {
+	if scale <= 0 || scale > 1 {
+		panic(fmt.Sprintf("invalid inset scale %v", scale))
+	}
+	opt := layoutOptions(opts)
+	scaled := FilterMultiOutput([]*Stream{videoOf(inset), videoOf(main)}, "scale2ref", nil, insetScale(scale))
+	x, y := fmt.Sprint(margin), fmt.Sprint(margin)
+	if corner == TopRight || corner == BottomRight {
+		x = fmt.Sprintf("W-w-%d", margin)
+	}
+	if corner == BottomLeft || corner == BottomRight {
+		y = fmt.Sprintf("H-h-%d", margin)
+	}
+	// the main video goes on when the inset ends
+	video = scaled.Get("1").Overlay(scaled.Get("0"), "pass", KwArgs{"x": x, "y": y})
+	return video, mixAudio([]*Stream{main, inset}, opt)
+}
+
+// insetScale are the scale2ref options of PictureInPicture. In scale2ref
+// iw, ih and a are the reference, the main video, while main_w, main_h and
+// main_a are the inset being scaled.
+func insetScale(scale float64) KwArgs {
+	return KwArgs{
+		"w": fmt.Sprintf("trunc(iw*%s/2)*2", formatFloat(scale)),
+		"h": "trunc(ow/main_a/2)*2",
+	}
+}
@@ -0,0 +1,145 @@
+package ffmpeg_go
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrid(t *testing.T) {
+	video, audio := Grid([]*Stream{Input("a.mp4"), Input("b.mp4"), Input("c.mp4")}, 2, 320, 180, LayoutOptions{MixAudio: true})
+	args := Output([]*Stream{video, audio}, "out.mp4").GetArgs()
+	assert.Equal(t, "[0:v]scale=320:180:force_original_aspect_ratio=decrease[s0];[s0]pad=320:180:(ow-iw)/2:(oh-ih)/2:color=black[s1];[s1]setsar=1[s2];"+
+		"[1:v]scale=320:180:force_original_aspect_ratio=decrease[s3];[s3]pad=320:180:(ow-iw)/2:(oh-ih)/2:color=black[s4];[s4]setsar=1[s5];"+
+		"[2:v]scale=320:180:force_original_aspect_ratio=decrease[s6];[s6]pad=320:180:(ow-iw)/2:(oh-ih)/2:color=black[s7];[s7]setsar=1[s8];"+
+		"[s2][s5][s8]xstack=fill=black:inputs=3:layout=0_0|320_0|0_180[s9];[0:a][1:a][2:a]amix=inputs=3[s10]", args[7])
+
+	video, audio = Grid([]*Stream{Input("a.mp4")}, 2, 320, 180)
+	assert.Nil(t, audio)
+	assert.Equal(t, "[0:v]scale=320:180:force_original_aspect_ratio=decrease[s0];[s0]pad=320:180:(ow-iw)/2:(oh-ih)/2:color=black[s1];[s1]setsar=1[s2]", video.Output("out.mp4").GetArgs()[3])
+
+	assert.Panics(t, func() { Grid([]*Stream{Input("a.mp4")}, 0, 320, 180) })
+}
+
+func TestSideBySide(t *testing.T) {
+	video, _ := SideBySide([]*Stream{Input("a.mp4"), Input("b.mp4")}, 720)
+	assert.Equal(t, "[0:v]scale=-2:720[s0];[s0]setsar=1[s1];[1:v]scale=-2:720[s2];[s2]setsar=1[s3];[s1][s3]hstack=inputs=2[s4]", video.Output("out.mp4").GetArgs()[5])
+
+	video, _ = Stack([]*Stream{Input("a.mp4"), Input("b.mp4")}, 1280)
+	assert.Equal(t, "[0:v]scale=1280:-2[s0];[s0]setsar=1[s1];[1:v]scale=1280:-2[s2];[s2]setsar=1[s3];[s1][s3]vstack=inputs=2[s4]", video.Output("out.mp4").GetArgs()[5])
+
+	assert.Panics(t, func() { SideBySide([]*Stream{Input("a.mp4")}, 720) })
+	assert.Panics(t, func() {
+		SideBySide([]*Stream{Input("a.mp4").Video(), Input("b.mp4")}, 720, LayoutOptions{MixAudio: true})
+	})
+}
+
+func TestPictureInPicture(t *testing.T) {
+	video, audio := PictureInPicture(Input("main.mp4"), Input("cam.mp4"), BottomRight, 0.25, 16, LayoutOptions{MixAudio: true})
+	args := Output([]*Stream{video, audio}, "out.mp4").GetArgs()
+	assert.Equal(t, "[0:v][1:v]scale2ref=h=trunc(ow/main_a/2)*2:w=trunc(iw*0.25/2)*2[s0][s1];[s1][s0]overlay=eof_action=pass:x=W-w-16:y=H-h-16[s2];"+
+		"[1:a][0:a]amix=inputs=2[s3]", args[5])
+
+	video, _ = PictureInPicture(Input("main.mp4"), Input("cam.mp4"), TopLeft, 0.5, 0)
+	assert.Contains(t, video.Output("out.mp4").GetArgs()[5], "overlay=eof_action=pass:x=0:y=0")
+}
+
+// evalExpr evaluates the +-*/ and trunc() subset of ffmpeg expressions.
+func evalExpr(t *testing.T, expr string, vars map[string]float64) float64 {
+	p := &exprParser{s: expr, vars: vars}
+	v := p.sum()
+	if p.err != nil || p.i != len(p.s) {
+		t.Fatalf("can't evaluate %q at %d: %v", expr, p.i, p.err)
+	}
+	return v
+}
+
+type exprParser struct {
+	s    string
+	i    int
+	vars map[string]float64
+	err  error
+}
+
+func (p *exprParser) sum() float64 {
+	v := p.product()
+	for p.i < len(p.s) && (p.s[p.i] == '+' || p.s[p.i] == '-') {
+		op := p.s[p.i]
+		p.i++
+		if op == '+' {
+			v += p.product()
+		} else {
+			v -= p.product()
+		}
+	}
+	return v
+}
+
+func (p *exprParser) product() float64 {
+	v := p.operand()
+	for p.i < len(p.s) && (p.s[p.i] == '*' || p.s[p.i] == '/') {
+		op := p.s[p.i]
+		p.i++
+		if op == '*' {
+			v *= p.operand()
+		} else {
+			v /= p.operand()
+		}
+	}
+	return v
+}
+
+func (p *exprParser) operand() float64 {
+	start := p.i
+	for p.i < len(p.s) && (p.s[p.i] == '_' || p.s[p.i] == '.' ||
+		'a' <= p.s[p.i] && p.s[p.i] <= 'z' || '0' <= p.s[p.i] && p.s[p.i] <= '9') {
+		p.i++
+	}
+	name := p.s[start:p.i]
+	if p.i < len(p.s) && p.s[p.i] == '(' {
+		p.i++
+		v := p.sum()
+		if p.i >= len(p.s) || p.s[p.i] != ')' {
+			p.err = fmt.Errorf("missing )")
+			return 0
+		}
+		p.i++
+		switch name {
+		case "":
+			return v
+		case "trunc":
+			return math.Trunc(v)
+		}
+		p.err = fmt.Errorf("unknown function %s", name)
+		return 0
+	}
+	if v, ok := p.vars[name]; ok {
+		return v
+	}
+	v, err := strconv.ParseFloat(name, 64)
+	if err != nil {
+		p.err = err
+	}
+	return v
+}
+
+func TestInsetScale(t *testing.T) {
+	// a 4:3 inset on a 16:9 main video
+	vars := map[string]float64{
+		"iw": 1920, "ih": 1080, "a": 1920.0 / 1080,
+		"main_w": 640, "main_h": 480, "main_a": 640.0 / 480,
+	}
+	args := insetScale(0.25)
+	vars["ow"] = evalExpr(t, args["w"].(string), vars)
+	assert.Equal(t, 480.0, vars["ow"])
+	assert.Equal(t, 360.0, evalExpr(t, args["h"].(string), vars))
+
+	// a portrait inset keeps its aspect ratio too
+	vars["main_w"], vars["main_h"], vars["main_a"] = 1080, 1920, 1080.0/1920
+	vars["ow"] = evalExpr(t, args["w"].(string), vars)
+	assert.Equal(t, 480.0, vars["ow"])
+	assert.Equal(t, 852.0, evalExpr(t, args["h"].(string), vars))
+}
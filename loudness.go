@@ -0,0 +1,141 @@
+package ffmpeg_go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Loudness is the EBU R128 measurement of the loudnorm filter.
+type Loudness struct {
+	// Integrated loudness in LUFS.
+	Integrated float64
+	// TruePeak in dBTP.
+	TruePeak float64
+	// LRA is the loudness range in LU.
+	LRA float64
+	// Threshold in LUFS.
+	Threshold float64
+	// TargetOffset is the gain in LU still needed to reach the target
+	// after normalization.
+	TargetOffset float64
+}
+
+// LoudnessTarget are the targets of loudnorm, zero values use loudnorm's
+// defaults (-24 LUFS, -2 dBTP, 7 LU).
+type LoudnessTarget struct {
+	I   float64
+	TP  float64
+	LRA float64
+}
+
+func (t LoudnessTarget) kwargs() KwArgs {
+	args := KwArgs{}
+	if t.I != 0 {
+		args["I"] = formatFloat(t.I)
+	}
+	if t.TP != 0 {
+		args["TP"] = formatFloat(t.TP)
+	}
+	if t.LRA != 0 {
+		args["LRA"] = formatFloat(t.LRA)
+	}
+	return args
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// MeasureLoudness measures the loudness of the audio of input with
+// “loudnorm=print_format=json“.
+func MeasureLoudness(ctx context.Context, input *Stream) (Loudness, error) {
+	return measureLoudness(ctx, input, LoudnessTarget{})
+}
+
+func measureLoudness(ctx context.Context, input *Stream, target LoudnessTarget) (Loudness, error) {
+	measured := audioOf(input).Filter("loudnorm", nil, target.kwargs(), KwArgs{"print_format": "json"})
+	parser := &loudnormParser{}
+	err := runWithLogParser(nullOutput(ctx, input.FfmpegPath, []*Stream{measured}), parser.parse)
+	if err != nil {
+		return Loudness{}, err
+	}
+	return parser.loudness()
+}
+
+// NormalizeLoudness measures the loudness of the audio of s first, and
+// returns s normalized to target by a linear loudnorm pass with the measured
+// values. The measurement runs with s.Context.
+//
+// loudnorm outputs 192kHz audio, set “ar“ on the output to resample.
+func (s *Stream) NormalizeLoudness(target LoudnessTarget) (*Stream, error) {
+	AssertType(s.Type, "FilterableStream", "loudnorm")
+	loudness, err := measureLoudness(s.Context, s, target)
+	if err != nil {
+		return nil, err
+	}
+	if math.IsInf(loudness.Integrated, 0) || math.IsInf(loudness.Threshold, 0) {
+		return nil, errors.New("can't normalize loudness of silent audio")
+	}
+	args := target.kwargs()
+	args["measured_I"] = formatFloat(loudness.Integrated)
+	args["measured_TP"] = formatFloat(loudness.TruePeak)
+	args["measured_LRA"] = formatFloat(loudness.LRA)
+	args["measured_thresh"] = formatFloat(loudness.Threshold)
+	args["offset"] = formatFloat(loudness.TargetOffset)
+	args["linear"] = "true"
+	args["print_format"] = "summary"
+	return audioOf(s).Filter("loudnorm", nil, args), nil
+}
+
+// loudnormParser parses the json loudnorm logs after the filter ends
+//
+//	[Parsed_loudnorm_0 @ 0x7f9]
+//	{
+//		"input_i" : "-27.61",
+//		...
+//	}
+type loudnormParser struct {
+	started bool
+	lines   []string
+}
+
+func (p *loudnormParser) parse(line string) {
+	if strings.Contains(line, "Parsed_loudnorm") {
+		p.started, p.lines = true, nil
+		return
+	}
+	if !p.started {
+		return
+	}
+	p.lines = append(p.lines, line)
+	if strings.HasPrefix(line, "}") {
+		p.started = false
+	}
+}
+
+func (p *loudnormParser) loudness() (Loudness, error) {
+	values := map[string]string{}
+	err := json.Unmarshal([]byte(strings.Join(p.lines, "\n")), &values)
+	if err != nil {
+		return Loudness{}, fmt.Errorf("parse loudnorm output fail: %w", err)
+	}
+	var l Loudness
+	for key, f := range map[string]*float64{
+		"input_i":       &l.Integrated,
+		"input_tp":      &l.TruePeak,
+		"input_lra":     &l.LRA,
+		"input_thresh":  &l.Threshold,
+		"target_offset": &l.TargetOffset,
+	} {
+		*f, err = strconv.ParseFloat(values[key], 64)
+		if err != nil {
+			return Loudness{}, fmt.Errorf("parse loudnorm %s fail: %w", key, err)
+		}
+	}
+	return l, nil
+}
@@ -0,0 +1,40 @@
+package ffmpeg_go
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testLoudnormLog = `[Parsed_loudnorm_0 @ 0x7f9]
+{
+	"input_i" : "-27.61",
+	"input_tp" : "-4.47",
+	"input_lra" : "18.06",
+	"input_thresh" : "-39.20",
+	"output_i" : "-16.58",
+	"output_tp" : "-1.50",
+	"output_lra" : "14.78",
+	"output_thresh" : "-27.71",
+	"normalization_type" : "dynamic",
+	"target_offset" : "0.58"
+}`
+
+func TestLoudnormParser(t *testing.T) {
+	p := &loudnormParser{}
+	for _, line := range strings.Split("size=N/A time=00:00:05.00 bitrate=N/A\n"+testLoudnormLog, "\n") {
+		p.parse(line)
+	}
+	l, err := p.loudness()
+	assert.Nil(t, err)
+	assert.Equal(t, Loudness{Integrated: -27.61, TruePeak: -4.47, LRA: 18.06, Threshold: -39.2, TargetOffset: 0.58}, l)
+
+	p = &loudnormParser{}
+	p.parse("[Parsed_loudnorm_0 @ 0x7f9]")
+	p.parse(`{"input_i" : "-inf", "input_tp" : "-inf", "input_lra" : "0.00", "input_thresh" : "-70.00", "target_offset" : "inf"}`)
+	l, err = p.loudness()
+	assert.Nil(t, err)
+	assert.True(t, math.IsInf(l.Integrated, -1))
+}
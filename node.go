@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/u2takey/go-utils/sets"
@@ -18,12 +19,23 @@ type Stream struct {
 	Context    context.Context
 }
 
+// RunHook is a Go side task that runs alongside the ffmpeg process, e.g. an
+// upload consuming ffmpeg's stdout. An error returned by f is joined into the
+// error returned by Run and kills the ffmpeg process.
 type RunHook struct {
-	f      func()
-	done   <-chan struct{}
+	f      func() error
 	closer interface {
-		Close() error
+		CloseWithError(err error) error
 	}
+	// drain hooks pass ffmpeg's output on, they are waited for before the
+	// closers are called
+	drain bool
+	// feed hooks pass input to ffmpeg, their closer is called as soon as
+	// they return and they aren't waited for once ffmpeg exited, as their
+	// reader may block
+	feed bool
+	// childFiles are inherited by ffmpeg and closed in Go once it started
+	childFiles []*os.File
 }
 
 func NewStream(node *Node, streamType string, label Label, selector Selector) *Stream {
@@ -93,6 +105,10 @@ type Node struct {
 	args                []string
 	kwargs              KwArgs
 	nodeType            string
+	// source is set on input nodes reading a remote url, see RegisterSource
+	source *remoteSource
+	// pipe is set on nodes bound to a Go reader or writer, see InputReader
+	pipe *goPipe
 }
 
 func NewNode(streamSpec []*Stream,
@@ -219,6 +235,9 @@ func (n *Node) Hash() int {
 	}
 	b += getHash(n.args)
 	b += getHash(n.kwargs)
+	if n.pipe != nil {
+		b += getHash(fmt.Sprintf("%p", n.pipe))
+	}
 	return b
 }
 
@@ -258,22 +277,9 @@ func (n *Node) GetFilter(outgoingEdges []DagEdge) string {
 	if n.nodeType != "FilterNode" {
 		panic("call GetFilter on non-FilterNode")
 	}
-	args, kwargs, ret := n.args, n.kwargs, ""
+	args := n.args
 	if n.name == "split" || n.name == "asplit" {
 		args = []string{fmt.Sprintf("%d", len(outgoingEdges))}
 	}
-	// args = Args(args).EscapeWith("\\'=:")
-	for _, k := range kwargs.EscapeWith("\\'=:").SortedKeys() {
-		v := getString(kwargs[k])
-		if v != "" {
-			args = append(args, fmt.Sprintf("%s=%s", k, v))
-		} else {
-			args = append(args, k)
-		}
-	}
-	ret = escapeChars(n.name, "\\'=:")
-	if len(args) > 0 {
-		ret += fmt.Sprintf("=%s", strings.Join(args, ":"))
-	}
-	return escapeChars(ret, "\\'[],;")
+	return filterString(n.name, args, n.kwargs)
 }
@@ -0,0 +1,181 @@
+package ffmpeg_go
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// goPipe connects an input node to a Go reader, or an output node to a Go
+// writer, through an extra file descriptor of the ffmpeg process.
+type goPipe struct {
+	reader io.Reader
+	writer io.Writer
+	// stdout binds the writer to ffmpeg's stdout if it is free
+	stdout bool
+}
+
+// InputReader reads the input from r, which ffmpeg sees as “pipe:3“,
+// “pipe:4“, ... so any number of Go readers can be used in one command.
+//
+// ffmpeg can't guess the format from a pipe, so “format“ should usually be
+// given in kwargs. Extra file descriptors are not supported on windows. The
+// pipes are bound by Run, so Compile panics for a graph with them.
+func InputReader(r io.Reader, kwargs ...KwArgs) *Stream {
+	s := Input("pipe:", kwargs...)
+	s.Node.pipe = &goPipe{reader: r}
+	return s
+}
+
+// OutputWriter is the same as Output, but writes the output to w through an
+// extra file descriptor. As with InputReader the graph can only be run, not
+// compiled.
+func OutputWriter(streams []*Stream, w io.Writer, kwargs ...KwArgs) *Stream {
+	o := Output(streams, "pipe:", kwargs...)
+	o.Node.pipe = &goPipe{writer: w}
+	return o
+}
+
+// OutputWriter writes the output to w through an extra file descriptor, see
+// InputReader.
+func (s *Stream) OutputWriter(w io.Writer, kwargs ...KwArgs) *Stream {
+	AssertType(s.Type, "FilterableStream", "output")
+	o := OutputWriter([]*Stream{s}, w, kwargs...)
+	o.Context = s.Context
+	return o
+}
+
+// fileCloser closes the Go side of a pipe as RunHook.closer, ffmpeg sees
+// EOF whatever the error.
+type fileCloser struct {
+	*os.File
+}
+
+func (f fileCloser) CloseWithError(err error) error {
+	return f.Close()
+}
+
+func pipeFilename(fd int) string {
+	if fd <= 1 {
+		return "pipe:"
+	}
+	return fmt.Sprintf("pipe:%d", fd)
+}
+
+// allocateFds returns the file descriptor used by each node bound to Go,
+// keyed by node hash. A streamed source takes stdin and an output sink
+// stdout if they are free, all other bindings get extra file descriptors in
+// command line order.
+func (s *Stream) allocateFds(sorted []DagNode) map[int]int {
+	fds := map[int]int{}
+	stdinFree := s.Context.Value("Stdin") == nil
+	stdoutFree := s.Context.Value("Stdout") == nil
+	next := 3
+	for _, d := range sorted {
+		n := d.(*Node)
+		streamed := n.source != nil && !n.source.seekable
+		switch {
+		case streamed && stdinFree:
+			fds[n.Hash()] = 0
+			stdinFree = false
+		case n.pipe != nil && n.pipe.stdout && stdoutFree:
+			fds[n.Hash()] = 1
+			stdoutFree = false
+		case streamed, n.pipe != nil:
+			fds[n.Hash()] = next
+			next++
+		}
+	}
+	return fds
+}
+
+// pipeReader returns the Go side reader of input node n.
+func (s *Stream) pipeReader(n *Node) io.Reader {
+	if n.source != nil {
+		return &sourceReader{ctx: s.Context, source: n.source}
+	}
+	return n.pipe.reader
+}
+
+// bindsAtRun tells if the graph has bindings that only Run sets up: pipes on
+// extra file descriptors and seekable sources, which are downloaded.
+func (s *Stream) bindsAtRun() bool {
+	sorted, _, err := s.topSort()
+	if err != nil {
+		panic(err)
+	}
+	for _, fd := range s.allocateFds(sorted) {
+		if fd >= 3 {
+			return true
+		}
+	}
+	for _, n := range s.nodesOfType("InputNode") {
+		if n.source != nil && n.source.seekable {
+			return true
+		}
+	}
+	return false
+}
+
+// bindPipes sets up cmd.ExtraFiles, the returned hooks copy between the Go
+// readers and writers and the pipes while ffmpeg is running.
+func (s *Stream) bindPipes(cmd *exec.Cmd) ([]*RunHook, error) {
+	sorted, _, err := s.topSort()
+	if err != nil {
+		return nil, err
+	}
+	fds := s.allocateFds(sorted)
+	var hooks []*RunHook
+	var files []*os.File
+	for _, d := range sorted {
+		n := d.(*Node)
+		fd, ok := fds[n.Hash()]
+		if !ok || fd < 3 {
+			continue
+		}
+		pr, pw, err := os.Pipe()
+		if err != nil {
+			for _, f := range files {
+				_ = f.Close()
+			}
+			return nil, err
+		}
+		files = append(files, pr, pw)
+		for len(cmd.ExtraFiles) <= fd-3 {
+			cmd.ExtraFiles = append(cmd.ExtraFiles, nil)
+		}
+		if n.nodeType == "InputNode" {
+			r := s.pipeReader(n)
+			cmd.ExtraFiles[fd-3] = pr
+			hooks = append(hooks, &RunHook{
+				f: func() error {
+					_, err := io.Copy(pw, r)
+					if errors.Is(err, syscall.EPIPE) {
+						// ffmpeg stopped reading, e.g. because of “-t“
+						return nil
+					}
+					return err
+				},
+				closer:     fileCloser{pw},
+				feed:       true,
+				childFiles: []*os.File{pr},
+			})
+		} else {
+			w := n.pipe.writer
+			cmd.ExtraFiles[fd-3] = pw
+			hooks = append(hooks, &RunHook{
+				f: func() error {
+					_, err := io.Copy(w, pr)
+					_ = pr.Close()
+					return err
+				},
+				drain:      true,
+				childFiles: []*os.File{pw},
+			})
+		}
+	}
+	return hooks, nil
+}
@@ -0,0 +1,67 @@
+package ffmpeg_go
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipeRun(t *testing.T) {
+	video := InputReader(strings.NewReader("video"), KwArgs{"format": "h264"})
+	audio := InputReader(strings.NewReader("audio"), KwArgs{"format": "aac"})
+	buf1, buf2 := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	out1 := OutputWriter([]*Stream{video, audio}, buf1, KwArgs{"format": "mpegts"})
+	out2 := audio.OutputWriter(buf2, KwArgs{"format": "adts"})
+	err := MergeOutputs(out1, out2).
+		SetFfmpegPath(fakeFfmpeg(t, "cat <&3 >&5; cat <&4 >&6")).
+		Run()
+	assert.Nil(t, err)
+	assert.Equal(t, "video", buf1.String())
+	assert.Equal(t, "audio", buf2.String())
+}
+
+type failWriter struct{}
+
+func (failWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write fail")
+}
+
+func TestPipeWriterError(t *testing.T) {
+	err := Input("dummy.mp4").OutputWriter(failWriter{}, KwArgs{"format": "mpegts"}).
+		SetFfmpegPath(fakeFfmpeg(t, "echo data >&3; exec sleep 10")).
+		Run()
+	assert.EqualError(t, err, "signal: killed; write fail")
+}
+
+func TestPipeReaderBlocks(t *testing.T) {
+	// the reader blocks after the first line, when ffmpeg already exited
+	blocked, unblock := io.Pipe()
+	defer unblock.Close()
+	r := io.MultiReader(strings.NewReader("video\n"), blocked)
+	done := make(chan error, 1)
+	go func() {
+		done <- InputReader(r, KwArgs{"format": "h264"}).Output("out.mp4").
+			SetFfmpegPath(fakeFfmpeg(t, "read line <&3")).
+			Run()
+	}()
+	select {
+	case err := <-done:
+		assert.Nil(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("run hangs on the blocked reader")
+	}
+}
+
+func TestPipeReaderError(t *testing.T) {
+	readErr := errors.New("read fail")
+	err := InputReader(iotest.ErrReader(readErr), KwArgs{"format": "h264"}).Output("out.mp4").
+		SetFfmpegPath(fakeFfmpeg(t, "cat <&3 >/dev/null")).
+		Run()
+	assert.True(t, errors.Is(err, readErr), err)
+}
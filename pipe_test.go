@@ -0,0 +1,36 @@
+package ffmpeg_go
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPipeArgs(t *testing.T) {
+	video := InputReader(strings.NewReader("video"), KwArgs{"format": "h264"})
+	audio := InputReader(strings.NewReader("audio"), KwArgs{"format": "aac"})
+	out1 := OutputWriter([]*Stream{video, audio}, bytes.NewBuffer(nil), KwArgs{"format": "mpegts"})
+	out2 := audio.OutputWriter(bytes.NewBuffer(nil), KwArgs{"format": "adts"})
+	assert.Equal(t, []string{
+		"-f", "h264", "-i", "pipe:3",
+		"-f", "aac", "-i", "pipe:4",
+		"-map", "0", "-map", "1", "-f", "mpegts", "pipe:5",
+		"-map", "1", "-f", "adts", "pipe:6",
+	}, MergeOutputs(out1, out2).GetArgs())
+}
+
+func TestPipeHash(t *testing.T) {
+	assert.NotEqual(t, InputReader(bytes.NewBuffer(nil)).Hash(), InputReader(bytes.NewBuffer(nil)).Hash())
+}
+
+func TestPipeCompile(t *testing.T) {
+	// extra file descriptors and downloads are bound by Run only
+	assert.Panics(t, func() { InputReader(strings.NewReader("video")).Output("out.mp4").Compile() })
+	assert.Panics(t, func() { Input("in.mp4").OutputWriter(bytes.NewBuffer(nil)).Compile() })
+	assert.Panics(t, func() { Input("mem://in.mp4", KwArgs{"seekable": true}).Output("out.mp4").Compile() })
+	// stdin and stdout are set on the command
+	cmd := Input("mem://in.ts").Output("out.mp4").Compile()
+	assert.NotNil(t, cmd.Stdin)
+}
@@ -3,46 +3,212 @@ package ffmpeg_go
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os/exec"
+	"strconv"
+	"sync"
 	"time"
 )
 
-// Probe Run ffprobe on the specified file and return a JSON representation of the output.
-func Probe(fileName string, kwargs ...KwArgs) (string, error) {
-	return ProbeWithTimeout(fileName, 0, MergeKwArgs(kwargs))
+var (
+	ffprobePathMu sync.RWMutex
+	ffprobePath   = "ffprobe"
+)
+
+// SetFfprobePath sets the ffprobe binary used by the Probe functions if
+// ProbeOptions.FfprobePath is empty, like Stream.SetFfmpegPath does for ffmpeg.
+func SetFfprobePath(path string) {
+	ffprobePathMu.Lock()
+	defer ffprobePathMu.Unlock()
+	ffprobePath = path
 }
 
-func ProbeWithTimeout(fileName string, timeOut time.Duration, kwargs KwArgs) (string, error) {
-	args := KwArgs{
+func getFfprobePath() string {
+	ffprobePathMu.RLock()
+	defer ffprobePathMu.RUnlock()
+	return ffprobePath
+}
+
+type ProbeOptions struct {
+	// FfprobePath overrides the path set by SetFfprobePath.
+	FfprobePath string
+	// KwArgs are added to the ffprobe arguments, e.g. {"select_streams": "v:0"}.
+	KwArgs KwArgs
+	// CacheKey identifies the input in the ProbeCache instead of the file
+	// identity, it is required to cache probes of readers.
+	CacheKey string
+}
+
+func mergeProbeOptions(opts []ProbeOptions) ProbeOptions {
+	opt := ProbeOptions{}
+	var kwargs []KwArgs
+	for _, o := range opts {
+		if o.FfprobePath != "" {
+			opt.FfprobePath = o.FfprobePath
+		}
+		if o.CacheKey != "" {
+			opt.CacheKey = o.CacheKey
+		}
+		kwargs = append(kwargs, o.KwArgs)
+	}
+	opt.KwArgs = MergeKwArgs(kwargs)
+	if opt.FfprobePath == "" {
+		opt.FfprobePath = getFfprobePath()
+	}
+	return opt
+}
+
+// probeCommand creates an ffprobe command with the GlobalCommandOptions
+// applied.
+func probeCommand(ctx context.Context, path string, args []string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, path, args...)
+	for _, option := range GlobalCommandOptions {
+		option(cmd)
+	}
+	return cmd
+}
+
+// runProbe runs ffprobe on fileName, reading the input from r if it isn't nil.
+// The output is looked up in and added to the ProbeCache if one is set.
+func runProbe(ctx context.Context, fileName string, r io.Reader, opt ProbeOptions) (string, error) {
+	args := append(ConvertKwargsToCmdLineArgs(opt.KwArgs), fileName)
+	cache := getProbeCache()
+	if cache != nil {
+		if key, ok := probeCacheKey(fileName, args, opt.CacheKey); ok {
+			if output, ok := cache.get(key); ok {
+				return output, nil
+			}
+			output, err := execProbe(ctx, r, opt.FfprobePath, args)
+			if err == nil {
+				// a failing cache only costs a probe next time
+				_ = cache.put(key, output)
+			}
+			return output, err
+		}
+	}
+	return execProbe(ctx, r, opt.FfprobePath, args)
+}
+
+func execProbe(ctx context.Context, r io.Reader, path string, args []string) (string, error) {
+	cmd := probeCommand(ctx, path, args)
+	if r != nil {
+		cmd.Stdin = r
+	}
+	buf := bytes.NewBuffer(nil)
+	stdErrBuf := bytes.NewBuffer(nil)
+	cmd.Stdout = buf
+	cmd.Stderr = stdErrBuf
+	err := cmd.Run()
+	if err != nil {
+		cmdErr := newCommandError(cmd, err)
+		cmdErr.Stderr = stdErrBuf.String()
+		return "", cmdErr
+	}
+	return buf.String(), nil
+}
+
+func defaultProbeArgs() KwArgs {
+	return KwArgs{
 		"show_format":  "",
 		"show_streams": "",
 		"of":           "json",
 	}
+}
+
+// ProbeContext runs ffprobe on the specified file and returns a JSON
+// representation of the output, ffprobe is killed if ctx is done.
+func ProbeContext(ctx context.Context, fileName string, opts ...ProbeOptions) (string, error) {
+	opt := mergeProbeOptions(append([]ProbeOptions{{KwArgs: defaultProbeArgs()}}, opts...))
+	return runProbe(ctx, fileName, nil, opt)
+}
+
+// Probe Run ffprobe on the specified file and return a JSON representation of the output.
+func Probe(fileName string, kwargs ...KwArgs) (string, error) {
+	return ProbeWithTimeout(fileName, 0, MergeKwArgs(kwargs))
+}
 
-	return ProbeWithTimeoutExec(fileName, timeOut, MergeKwArgs([]KwArgs{args, kwargs}))
+func ProbeWithTimeout(fileName string, timeOut time.Duration, kwargs KwArgs) (string, error) {
+	return ProbeWithTimeoutExec(fileName, timeOut, MergeKwArgs([]KwArgs{defaultProbeArgs(), kwargs}))
 }
 
 func ProbeWithTimeoutExec(fileName string, timeOut time.Duration, kwargs KwArgs) (string, error) {
-	args := ConvertKwargsToCmdLineArgs(kwargs)
-	args = append(args, fileName)
-	ctx := context.Background()
+	ctx, cancel := timeoutContext(timeOut)
+	defer cancel()
+	return runProbe(ctx, fileName, nil, mergeProbeOptions([]ProbeOptions{{KwArgs: kwargs}}))
+}
+
+func timeoutContext(timeOut time.Duration) (context.Context, context.CancelFunc) {
 	if timeOut > 0 {
-		var cancel func()
-		ctx, cancel = context.WithTimeout(context.Background(), timeOut)
-		defer cancel()
+		return context.WithTimeout(context.Background(), timeOut)
 	}
-	cmd := exec.CommandContext(ctx, "ffprobe", args...)
-	buf := bytes.NewBuffer(nil)
-	stdErrBuf := bytes.NewBuffer(nil)
-	cmd.Stdout = buf
-	cmd.Stderr = stdErrBuf
-	for _, option := range GlobalCommandOptions {
-		option(cmd)
+	return context.WithCancel(context.Background())
+}
+
+// probeFileOf returns the file name of s if it is a plain file input, which
+// can be probed before running ffmpeg.
+func probeFileOf(s *Stream) (string, bool) {
+	if s.Node.nodeType != "InputNode" || s.Node.source != nil || s.Node.pipe != nil {
+		return "", false
 	}
-	err := cmd.Run()
+	return s.Node.kwargs.GetString("filename"), true
+}
+
+// probedMedia is the part of the ffprobe output the helpers need.
+type probedMedia struct {
+	Duration time.Duration
+	Streams  []probedStream
+}
+
+type probedStream struct {
+	Index         int    `json:"index"`
+	CodecType     string `json:"codec_type"`
+	CodecName     string `json:"codec_name"`
+	Profile       string `json:"profile"`
+	Level         int    `json:"level"`
+	Width         int    `json:"width"`
+	Height        int    `json:"height"`
+	PixFmt        string `json:"pix_fmt"`
+	FrameRate     string `json:"r_frame_rate"`
+	SampleRate    string `json:"sample_rate"`
+	Channels      int    `json:"channels"`
+	ChannelLayout string `json:"channel_layout"`
+	TimeBase      string `json:"time_base"`
+}
+
+// stream returns the first stream of codecType, e.g. "video".
+func (m probedMedia) stream(codecType string) (probedStream, bool) {
+	for _, s := range m.Streams {
+		if s.CodecType == codecType {
+			return s, true
+		}
+	}
+	return probedStream{}, false
+}
+
+// probeMedia probes format and streams of fileName.
+func probeMedia(ctx context.Context, fileName string) (probedMedia, error) {
+	data, err := ProbeContext(ctx, fileName)
 	if err != nil {
-		return "", fmt.Errorf("[%s] %w", string(stdErrBuf.Bytes()), err)
+		return probedMedia{}, err
+	}
+	info := struct {
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+		Streams []probedStream `json:"streams"`
+	}{}
+	if err := json.Unmarshal([]byte(data), &info); err != nil {
+		return probedMedia{}, fmt.Errorf("parse probe of %s fail: %w", fileName, err)
+	}
+	m := probedMedia{Streams: info.Streams}
+	if info.Format.Duration != "" {
+		seconds, err := strconv.ParseFloat(info.Format.Duration, 64)
+		if err != nil {
+			return probedMedia{}, fmt.Errorf("parse duration of %s fail: %w", fileName, err)
+		}
+		m.Duration = secondsToDuration(seconds)
 	}
-	return string(buf.Bytes()), nil
+	return m, nil
 }
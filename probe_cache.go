@@ -0,0 +1,199 @@
+package ffmpeg_go
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ProbeCache caches ffprobe outputs of the Probe functions once it is set
+// with SetProbeCache. Files are keyed by path, size and modification time,
+// readers and remote inputs only with ProbeOptions.CacheKey.
+type ProbeCache struct {
+	mu      sync.Mutex
+	size    int
+	dir     string
+	entries *list.List
+	index   map[string]*list.Element
+	stats   ProbeCacheStats
+}
+
+type ProbeCacheStats struct {
+	Hits   uint64
+	Misses uint64
+	// DiskHits are the hits found on disk but not in memory, they are
+	// counted in Hits too.
+	DiskHits uint64
+}
+
+type probeCacheEntry struct {
+	Key    string `json:"key"`
+	Output string `json:"output"`
+}
+
+// NewProbeCache creates a cache keeping the size most recently used outputs
+// in memory. If dir isn't empty, outputs are stored there as json files too,
+// so that they survive the process.
+func NewProbeCache(size int, dir string) (*ProbeCache, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("invalid probe cache size %d", size)
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+	}
+	return &ProbeCache{size: size, dir: dir, entries: list.New(), index: map[string]*list.Element{}}, nil
+}
+
+var (
+	probeCacheMu sync.RWMutex
+	probeCache   *ProbeCache
+)
+
+// SetProbeCache sets the cache used by the Probe functions, nil disables
+// caching.
+func SetProbeCache(c *ProbeCache) {
+	probeCacheMu.Lock()
+	defer probeCacheMu.Unlock()
+	probeCache = c
+}
+
+func getProbeCache() *ProbeCache {
+	probeCacheMu.RLock()
+	defer probeCacheMu.RUnlock()
+	return probeCache
+}
+
+func (c *ProbeCache) Stats() ProbeCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Len returns the number of outputs in memory.
+func (c *ProbeCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries.Len()
+}
+
+// Purge removes all outputs, from disk too.
+func (c *ProbeCache) Purge() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries.Init()
+	c.index = map[string]*list.Element{}
+	if c.dir == "" {
+		return nil
+	}
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := os.Remove(f); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *ProbeCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.index[key]; ok {
+		c.entries.MoveToFront(e)
+		c.stats.Hits++
+		return e.Value.(*probeCacheEntry).Output, true
+	}
+	if c.dir != "" {
+		data, err := ioutil.ReadFile(c.diskPath(key))
+		entry := &probeCacheEntry{}
+		// the key is compared in case of hash collisions
+		if err == nil && json.Unmarshal(data, entry) == nil && entry.Key == key {
+			c.add(entry)
+			c.stats.Hits++
+			c.stats.DiskHits++
+			return entry.Output, true
+		}
+	}
+	c.stats.Misses++
+	return "", false
+}
+
+func (c *ProbeCache) put(key, output string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry := &probeCacheEntry{Key: key, Output: output}
+	if e, ok := c.index[key]; ok {
+		e.Value = entry
+		c.entries.MoveToFront(e)
+	} else {
+		c.add(entry)
+	}
+	if c.dir == "" {
+		return nil
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	// write and rename so that concurrent processes never read a partial file
+	tmp, err := ioutil.TempFile(c.dir, ".probe_")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.diskPath(key))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func (c *ProbeCache) add(entry *probeCacheEntry) {
+	c.index[entry.Key] = c.entries.PushFront(entry)
+	for c.entries.Len() > c.size {
+		last := c.entries.Back()
+		c.entries.Remove(last)
+		delete(c.index, last.Value.(*probeCacheEntry).Key)
+	}
+}
+
+func (c *ProbeCache) diskPath(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// probeCacheKey returns the cache key of a probe of fileName with args, or
+// false if the input can't be identified.
+func probeCacheKey(fileName string, args []string, cacheKey string) (string, bool) {
+	identity := cacheKey
+	if identity == "" {
+		if fileName == "-" || strings.Contains(fileName, "://") {
+			return "", false
+		}
+		info, err := os.Stat(fileName)
+		if err != nil || !info.Mode().IsRegular() {
+			return "", false
+		}
+		if abs, err := filepath.Abs(fileName); err == nil {
+			fileName = abs
+		}
+		identity = fmt.Sprintf("%s\x00%d\x00%d", fileName, info.Size(), info.ModTime().UnixNano())
+	}
+	return identity + "\x00" + strings.Join(args, "\x00"), true
+}
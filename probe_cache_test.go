@@ -0,0 +1,70 @@
+package ffmpeg_go
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProbeCacheLRU(t *testing.T) {
+	c, err := NewProbeCache(2, "")
+	assert.Nil(t, err)
+	assert.Nil(t, c.put("a", "1"))
+	assert.Nil(t, c.put("b", "2"))
+	_, ok := c.get("a")
+	assert.True(t, ok)
+	assert.Nil(t, c.put("c", "3"))
+	_, ok = c.get("b")
+	assert.False(t, ok)
+	output, ok := c.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, "1", output)
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, ProbeCacheStats{Hits: 2, Misses: 1}, c.Stats())
+}
+
+func TestProbeCacheDisk(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewProbeCache(1, dir)
+	assert.Nil(t, err)
+	assert.Nil(t, c.put("a", `{"format": {}}`))
+	assert.Nil(t, c.put("b", "2"))
+
+	c, err = NewProbeCache(1, dir)
+	assert.Nil(t, err)
+	output, ok := c.get("a")
+	assert.True(t, ok)
+	assert.Equal(t, `{"format": {}}`, output)
+	assert.Equal(t, ProbeCacheStats{Hits: 1, DiskHits: 1}, c.Stats())
+
+	assert.Nil(t, c.Purge())
+	_, ok = c.get("b")
+	assert.False(t, ok)
+}
+
+func TestProbeCacheKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "in.mp4")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("data"), 0644))
+	key, ok := probeCacheKey(path, []string{"-show_format", path}, "")
+	assert.True(t, ok)
+
+	other, ok := probeCacheKey(path, []string{"-show_streams", path}, "")
+	assert.True(t, ok)
+	assert.NotEqual(t, key, other)
+
+	assert.Nil(t, os.Chtimes(path, time.Now(), time.Now().Add(time.Hour)))
+	other, ok = probeCacheKey(path, []string{"-show_format", path}, "")
+	assert.True(t, ok)
+	assert.NotEqual(t, key, other)
+
+	_, ok = probeCacheKey("-", []string{"-"}, "")
+	assert.False(t, ok)
+	_, ok = probeCacheKey("s3://bucket/in.mp4", nil, "")
+	assert.False(t, ok)
+	_, ok = probeCacheKey("-", []string{"-"}, "upload-1")
+	assert.True(t, ok)
+}
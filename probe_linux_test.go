@@ -0,0 +1,141 @@
+package ffmpeg_go
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// withFakeFfprobe runs ffprobe commands with a fake script while f runs.
+func withFakeFfprobe(t *testing.T, script string, f func()) {
+	path := fakeFfmpeg(t, script)
+	GlobalCommandOptions = append(GlobalCommandOptions, func(cmd *exec.Cmd) {
+		cmd.Path, cmd.Err = path, nil
+	})
+	defer func() {
+		GlobalCommandOptions = GlobalCommandOptions[0 : len(GlobalCommandOptions)-1]
+	}()
+	f()
+}
+
+const testPacketLog = `packet|stream_index=0|pts_time=0.000000|dts_time=-0.080000|size=5000|flags=K_
+packet|stream_index=1|pts_time=0.000000|dts_time=0.000000|size=300|flags=K_
+packet|stream_index=0|pts_time=0.160000|dts_time=-0.040000|size=1000|flags=__
+packet|stream_index=0|pts_time=0.080000|dts_time=0.000000|size=500|flags=__
+packet|stream_index=0|pts_time=1.000000|dts_time=1.000000|size=4000|flags=K_
+packet|stream_index=0|pts_time=1.040000|dts_time=1.040000|size=1000|flags=__`
+
+func TestProbePackets(t *testing.T) {
+	withFakeFfprobe(t, "cat <<'EOF'\n"+testPacketLog+"\nEOF", func() {
+		keyframes, err := Keyframes(context.Background(), "in.mp4", "")
+		assert.Nil(t, err)
+		assert.Equal(t, []time.Duration{0, time.Second}, keyframes)
+
+		gops, err := GOPLengths(context.Background(), "in.mp4", "")
+		assert.Nil(t, err)
+		assert.Equal(t, []int{3, 2}, gops)
+
+		bitrate, err := BitratePerSecond(context.Background(), "in.mp4", "")
+		assert.Nil(t, err)
+		assert.Equal(t, []int64{800 * 8, 5000 * 8}, bitrate)
+	})
+}
+
+func TestProbePacketsError(t *testing.T) {
+	withFakeFfprobe(t, "echo 'in.mp4: No such file or directory' >&2; exit 1", func() {
+		it, err := ProbePackets(context.Background(), "in.mp4", "v:0")
+		assert.Nil(t, err)
+		assert.False(t, it.Next())
+		assert.EqualError(t, it.Err(), "[in.mp4: No such file or directory\n] exit status 1")
+		assert.Equal(t, it.Err(), it.Close())
+	})
+	withFakeFfprobe(t, "exit 1", func() {
+		_, err := Probe("in.mp4")
+		var exitErr *exec.ExitError
+		assert.True(t, errors.As(err, &exitErr))
+	})
+}
+
+func TestProbeFramesClose(t *testing.T) {
+	withFakeFfprobe(t, "echo 'frame|stream_index=0|key_frame=1'; exec sleep 10", func() {
+		start := time.Now()
+		it, err := ProbeFrames(context.Background(), "in.mp4", "v:0")
+		assert.Nil(t, err)
+		assert.True(t, it.Next())
+		assert.True(t, it.Frame().Keyframe)
+		assert.Nil(t, it.Close())
+		assert.True(t, time.Since(start) < 5*time.Second)
+	})
+}
+
+func TestProbeContext(t *testing.T) {
+	ffprobe := fakeFfmpeg(t, `echo "$@" > "$(dirname "$0")/args"; echo '{}'`)
+	data, err := ProbeContext(context.Background(), "in.mp4", ProbeOptions{FfprobePath: ffprobe, KwArgs: KwArgs{"select_streams": "v:0"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "{}\n", data)
+	assert.Equal(t, "-of json -select_streams v:0 -show_format -show_streams in.mp4\n", fakeFfmpegArgs(t, ffprobe))
+
+	SetFfprobePath(ffprobe)
+	defer SetFfprobePath("ffprobe")
+	_, err = ProbeReaderWithTimeoutExec(strings.NewReader(""), time.Second, KwArgs{"show_format": ""})
+	assert.Nil(t, err)
+	assert.Equal(t, "-show_format -\n", fakeFfmpegArgs(t, ffprobe))
+}
+
+func TestProbeReaderGlobalCommandOptions(t *testing.T) {
+	withFakeFfprobe(t, "echo '{}'", func() {
+		data, err := ProbeReader(strings.NewReader(""))
+		assert.Nil(t, err)
+		assert.Equal(t, "{}\n", data)
+	})
+}
+
+func TestProbeContextCancel(t *testing.T) {
+	ffprobe := fakeFfmpeg(t, "echo 'probing' >&2; exec sleep 10")
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err := ProbeContext(ctx, "in.mp4", ProbeOptions{FfprobePath: ffprobe})
+	var cmdErr *CommandError
+	assert.True(t, errors.As(err, &cmdErr))
+	assert.Equal(t, ffprobe, cmdErr.Path)
+	assert.Equal(t, "probing\n", cmdErr.Stderr)
+	assert.Equal(t, -1, cmdErr.ExitCode())
+}
+
+func TestProbeCache(t *testing.T) {
+	ffprobe := fakeFfmpeg(t, `echo x >> "$(dirname "$0")/calls"; echo '{}'`)
+	path := filepath.Join(t.TempDir(), "in.mp4")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("data"), 0644))
+	cache, err := NewProbeCache(10, "")
+	assert.Nil(t, err)
+	SetProbeCache(cache)
+	defer SetProbeCache(nil)
+
+	opt := ProbeOptions{FfprobePath: ffprobe}
+	for i := 0; i < 3; i++ {
+		data, err := ProbeContext(context.Background(), path, opt)
+		assert.Nil(t, err)
+		assert.Equal(t, "{}\n", data)
+	}
+	_, err = ProbeReaderContext(context.Background(), strings.NewReader("data"), opt)
+	assert.Nil(t, err)
+	_, err = ProbeReaderContext(context.Background(), strings.NewReader("data"), opt)
+	assert.Nil(t, err)
+	opt.CacheKey = "upload-1"
+	_, err = ProbeReaderContext(context.Background(), strings.NewReader("data"), opt)
+	assert.Nil(t, err)
+	_, err = ProbeReaderContext(context.Background(), strings.NewReader("data"), opt)
+	assert.Nil(t, err)
+
+	calls, err := ioutil.ReadFile(filepath.Join(filepath.Dir(ffprobe), "calls"))
+	assert.Nil(t, err)
+	assert.Equal(t, "x\nx\nx\nx\n", string(calls))
+	assert.Equal(t, ProbeCacheStats{Hits: 3, Misses: 2}, cache.Stats())
+}
@@ -0,0 +1,362 @@
+package ffmpeg_go
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"math"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NoTimestamp is the time of packets and frames ffprobe reports as "N/A".
+const NoTimestamp = time.Duration(math.MinInt64)
+
+type Packet struct {
+	StreamIndex int
+	CodecType   string
+	PTS         time.Duration
+	DTS         time.Duration
+	Duration    time.Duration
+	Size        int
+	// Flags are the packet flags, e.g. "K_" for a keyframe.
+	Flags    string
+	Keyframe bool
+}
+
+type Frame struct {
+	StreamIndex int
+	MediaType   string
+	// PTS is the best effort timestamp of the frame.
+	PTS      time.Duration
+	Duration time.Duration
+	// PacketSize is the size of the packet the frame was decoded from.
+	PacketSize int
+	Keyframe   bool
+	// PictType is "I", "P" or "B" for video frames.
+	PictType string
+	Width    int
+	Height   int
+}
+
+// probeRecords runs ffprobe with compact output and decodes one section per
+// line, so that a record is available as soon as ffprobe printed it.
+type probeRecords struct {
+	cmd     *exec.Cmd
+	cancel  context.CancelFunc
+	stdout  io.ReadCloser
+	stderr  *bytes.Buffer
+	scanner *bufio.Scanner
+	section string
+	values  map[string]string
+	err     error
+	done    bool
+}
+
+func startProbeRecords(ctx context.Context, fileName, section, streamSel string, opts []ProbeOptions) (*probeRecords, error) {
+	args := KwArgs{
+		"show_" + section + "s": "",
+		"of":                    "compact=p=1:nk=0",
+		"v":                     "error",
+	}
+	if streamSel != "" {
+		args["select_streams"] = streamSel
+	}
+	opt := mergeProbeOptions(append([]ProbeOptions{{KwArgs: args}}, opts...))
+	ctx, cancel := context.WithCancel(ctx)
+	cmd := probeCommand(ctx, opt.FfprobePath, append(ConvertKwargsToCmdLineArgs(opt.KwArgs), fileName))
+	stderr := bytes.NewBuffer(nil)
+	cmd.Stderr = stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, newCommandError(cmd, err)
+	}
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return &probeRecords{
+		cmd:     cmd,
+		cancel:  cancel,
+		stdout:  stdout,
+		stderr:  stderr,
+		scanner: scanner,
+		section: section,
+	}, nil
+}
+
+func (r *probeRecords) next() bool {
+	if r.done {
+		return false
+	}
+	for r.scanner.Scan() {
+		values, ok := parseCompactLine(r.scanner.Text(), r.section)
+		if ok {
+			r.values = values
+			return true
+		}
+	}
+	r.finish(r.scanner.Err())
+	return false
+}
+
+func (r *probeRecords) finish(err error) {
+	if r.done {
+		return
+	}
+	r.done = true
+	if err != nil {
+		r.cancel()
+	}
+	waitErr := r.cmd.Wait()
+	r.cancel()
+	if err == nil {
+		err = waitErr
+	}
+	if err != nil {
+		cmdErr := newCommandError(r.cmd, err)
+		cmdErr.Stderr = r.stderr.String()
+		r.err = cmdErr
+	}
+}
+
+func (r *probeRecords) close() error {
+	if !r.done {
+		// stop ffprobe early, the error of the killed process is expected
+		r.cancel()
+		_ = r.stdout.Close()
+		_ = r.cmd.Wait()
+		r.done = true
+	}
+	return r.err
+}
+
+// parseCompactLine parses a “section|key=value|...“ line of ffprobe's compact
+// writer, lines of other sections (e.g. side data) are skipped.
+func parseCompactLine(line, section string) (map[string]string, bool) {
+	fields := strings.Split(line, "|")
+	if len(fields) == 0 || fields[0] != section {
+		return nil, false
+	}
+	values := make(map[string]string, len(fields)-1)
+	for _, f := range fields[1:] {
+		if i := strings.IndexByte(f, '='); i >= 0 {
+			values[f[:i]] = f[i+1:]
+		}
+	}
+	return values, true
+}
+
+func compactTime(values map[string]string, keys ...string) time.Duration {
+	for _, key := range keys {
+		if f, err := strconv.ParseFloat(values[key], 64); err == nil {
+			return secondsToDuration(f)
+		}
+	}
+	return NoTimestamp
+}
+
+func compactInt(values map[string]string, key string) int {
+	i, _ := strconv.Atoi(values[key])
+	return i
+}
+
+func packetOf(values map[string]string) Packet {
+	p := Packet{
+		StreamIndex: compactInt(values, "stream_index"),
+		CodecType:   values["codec_type"],
+		PTS:         compactTime(values, "pts_time"),
+		DTS:         compactTime(values, "dts_time"),
+		Duration:    compactTime(values, "duration_time"),
+		Size:        compactInt(values, "size"),
+		Flags:       values["flags"],
+	}
+	p.Keyframe = strings.HasPrefix(p.Flags, "K")
+	if p.Duration == NoTimestamp {
+		p.Duration = 0
+	}
+	return p
+}
+
+func frameOfValues(values map[string]string) Frame {
+	f := Frame{
+		StreamIndex: compactInt(values, "stream_index"),
+		MediaType:   values["media_type"],
+		PTS:         compactTime(values, "best_effort_timestamp_time", "pts_time", "pkt_dts_time"),
+		// pkt_duration_time was renamed to duration_time in ffmpeg 5.1
+		Duration:   compactTime(values, "duration_time", "pkt_duration_time"),
+		PacketSize: compactInt(values, "pkt_size"),
+		Keyframe:   values["key_frame"] == "1",
+		PictType:   values["pict_type"],
+		Width:      compactInt(values, "width"),
+		Height:     compactInt(values, "height"),
+	}
+	if f.Duration == NoTimestamp {
+		f.Duration = 0
+	}
+	return f
+}
+
+// PacketIterator iterates the packets of ProbePackets, it must be closed if
+// not iterated to the end.
+//
+//	it, err := ProbePackets(ctx, "input.mp4", "v:0")
+//	...
+//	defer it.Close()
+//	for it.Next() {
+//		p := it.Packet()
+//	}
+//	err = it.Err()
+type PacketIterator struct {
+	records *probeRecords
+}
+
+// ProbePackets runs ffprobe with “-show_packets“ on the streams of fileName
+// selected by streamSel (e.g. "v:0", all streams if empty), without decoding
+// them.
+func ProbePackets(ctx context.Context, fileName, streamSel string, opts ...ProbeOptions) (*PacketIterator, error) {
+	records, err := startProbeRecords(ctx, fileName, "packet", streamSel, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &PacketIterator{records: records}, nil
+}
+
+// Next advances to the next packet, it returns false at the end or on error.
+func (it *PacketIterator) Next() bool {
+	return it.records.next()
+}
+
+func (it *PacketIterator) Packet() Packet {
+	return packetOf(it.records.values)
+}
+
+// Err returns the error which ended the iteration, if any.
+func (it *PacketIterator) Err() error {
+	return it.records.err
+}
+
+// Close stops ffprobe if it is still running.
+func (it *PacketIterator) Close() error {
+	return it.records.close()
+}
+
+// FrameIterator iterates the frames of ProbeFrames, like PacketIterator.
+type FrameIterator struct {
+	records *probeRecords
+}
+
+// ProbeFrames runs ffprobe with “-show_frames“ on the streams of fileName
+// selected by streamSel, which decodes them.
+func ProbeFrames(ctx context.Context, fileName, streamSel string, opts ...ProbeOptions) (*FrameIterator, error) {
+	records, err := startProbeRecords(ctx, fileName, "frame", streamSel, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &FrameIterator{records: records}, nil
+}
+
+func (it *FrameIterator) Next() bool {
+	return it.records.next()
+}
+
+func (it *FrameIterator) Frame() Frame {
+	return frameOfValues(it.records.values)
+}
+
+func (it *FrameIterator) Err() error {
+	return it.records.err
+}
+
+func (it *FrameIterator) Close() error {
+	return it.records.close()
+}
+
+// Keyframes returns the timestamps of the keyframes of the first stream
+// selected by streamSel, e.g. "v:0".
+func Keyframes(ctx context.Context, fileName, streamSel string, opts ...ProbeOptions) ([]time.Duration, error) {
+	it, err := ProbePackets(ctx, fileName, streamSel, append(opts, ProbeOptions{KwArgs: KwArgs{"show_entries": "packet=stream_index,pts_time,dts_time,flags"}})...)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var keyframes []time.Duration
+	stream := -1
+	for it.Next() {
+		p := it.Packet()
+		if stream < 0 {
+			stream = p.StreamIndex
+		}
+		if p.StreamIndex != stream || !p.Keyframe {
+			continue
+		}
+		t := p.PTS
+		if t == NoTimestamp {
+			t = p.DTS
+		}
+		keyframes = append(keyframes, t)
+	}
+	return keyframes, it.Err()
+}
+
+// GOPLengths returns the number of packets from each keyframe to the next of
+// the first stream selected by streamSel. Packets before the first keyframe
+// are ignored.
+func GOPLengths(ctx context.Context, fileName, streamSel string, opts ...ProbeOptions) ([]int, error) {
+	it, err := ProbePackets(ctx, fileName, streamSel, append(opts, ProbeOptions{KwArgs: KwArgs{"show_entries": "packet=stream_index,flags"}})...)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var gops []int
+	stream := -1
+	for it.Next() {
+		p := it.Packet()
+		if stream < 0 {
+			stream = p.StreamIndex
+		}
+		if p.StreamIndex != stream {
+			continue
+		}
+		if p.Keyframe {
+			gops = append(gops, 1)
+		} else if len(gops) > 0 {
+			gops[len(gops)-1]++
+		}
+	}
+	return gops, it.Err()
+}
+
+// BitratePerSecond returns the bits per second of the streams selected by
+// streamSel, bucketed by packet DTS: element i is the number of bits of the
+// packets from second i to i+1.
+func BitratePerSecond(ctx context.Context, fileName, streamSel string, opts ...ProbeOptions) ([]int64, error) {
+	it, err := ProbePackets(ctx, fileName, streamSel, append(opts, ProbeOptions{KwArgs: KwArgs{"show_entries": "packet=stream_index,pts_time,dts_time,size"}})...)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+	var bitrate []int64
+	for it.Next() {
+		p := it.Packet()
+		t := p.DTS
+		if t == NoTimestamp {
+			t = p.PTS
+		}
+		if t == NoTimestamp || t < 0 {
+			continue
+		}
+		i := int(t / time.Second)
+		for len(bitrate) <= i {
+			bitrate = append(bitrate, 0)
+		}
+		bitrate[i] += int64(p.Size) * 8
+	}
+	return bitrate, it.Err()
+}
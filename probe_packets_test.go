@@ -0,0 +1,38 @@
+package ffmpeg_go
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCompactLine(t *testing.T) {
+	values, ok := parseCompactLine("packet|codec_type=video|stream_index=0|pts=1024|pts_time=0.080000|dts=N/A|dts_time=N/A|duration_time=0.040000|size=2419|pos=48|flags=K_", "packet")
+	assert.True(t, ok)
+	assert.Equal(t, Packet{
+		StreamIndex: 0,
+		CodecType:   "video",
+		PTS:         80 * time.Millisecond,
+		DTS:         NoTimestamp,
+		Duration:    40 * time.Millisecond,
+		Size:        2419,
+		Flags:       "K_",
+		Keyframe:    true,
+	}, packetOf(values))
+
+	_, ok = parseCompactLine("side_data|side_data_type=H.26[45] User Data Unregistered SEI message", "frame")
+	assert.False(t, ok)
+
+	values, ok = parseCompactLine("frame|media_type=video|stream_index=0|key_frame=0|pts_time=N/A|pkt_dts_time=0.040000|best_effort_timestamp_time=0.040000|pkt_duration_time=0.040000|pkt_size=311|width=320|height=240|pict_type=B", "frame")
+	assert.True(t, ok)
+	assert.Equal(t, Frame{
+		MediaType:  "video",
+		PTS:        40 * time.Millisecond,
+		Duration:   40 * time.Millisecond,
+		PacketSize: 311,
+		PictType:   "B",
+		Width:      320,
+		Height:     240,
+	}, frameOfValues(values))
+}
@@ -0,0 +1,209 @@
+package ffmpeg_go
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BlackDetectOptions are the options of the blackdetect filter, zero values
+// use the filter's defaults.
+type BlackDetectOptions struct {
+	// MinDuration is the shortest black interval reported (“d“).
+	MinDuration time.Duration
+	// PictureThreshold is the ratio of black pixels for a black frame (“pic_th“).
+	PictureThreshold float64
+	// PixelThreshold is the luminance below which a pixel is black (“pix_th“).
+	PixelThreshold float64
+}
+
+// FreezeDetectOptions are the options of the freezedetect filter, zero
+// values use the filter's defaults.
+type FreezeDetectOptions struct {
+	// MinDuration is the shortest freeze reported (“d“).
+	MinDuration time.Duration
+	// Noise is the noise tolerance in dB (“n“), e.g. -60.
+	Noise float64
+}
+
+// CropDetectOptions are the options of the cropdetect filter, zero values
+// use the filter's defaults.
+type CropDetectOptions struct {
+	// Limit is the black threshold (“limit“).
+	Limit float64
+	// Round is the value width and height are divisible by (“round“).
+	Round int
+}
+
+// QCOptions selects the detectors run by AnalyzeVideo, nil ones are skipped.
+type QCOptions struct {
+	Black  *BlackDetectOptions
+	Freeze *FreezeDetectOptions
+	Crop   *CropDetectOptions
+}
+
+type CropRect struct {
+	X, Y, W, H int
+}
+
+type QCReport struct {
+	Black  []Interval
+	Freeze []Interval
+	// Crop is the bounding box of the non black area over all frames, it is
+	// nil if crop detection is disabled or no frame was analyzed.
+	Crop *CropRect
+}
+
+// AnalyzeVideo runs the selected detectors on the video of input in a single
+// decode pass, splitting the video with a split node if more than one is
+// selected.
+func AnalyzeVideo(ctx context.Context, input *Stream, opts QCOptions) (QCReport, error) {
+	type detector struct {
+		filter string
+		args   KwArgs
+	}
+	var detectors []detector
+	if o := opts.Black; o != nil {
+		args := KwArgs{}
+		if o.MinDuration > 0 {
+			args["d"] = formatSeconds(o.MinDuration)
+		}
+		if o.PictureThreshold > 0 {
+			args["pic_th"] = formatFloat(o.PictureThreshold)
+		}
+		if o.PixelThreshold > 0 {
+			args["pix_th"] = formatFloat(o.PixelThreshold)
+		}
+		detectors = append(detectors, detector{"blackdetect", args})
+	}
+	if o := opts.Freeze; o != nil {
+		args := KwArgs{}
+		if o.MinDuration > 0 {
+			args["d"] = formatSeconds(o.MinDuration)
+		}
+		if o.Noise != 0 {
+			args["n"] = formatFloat(o.Noise) + "dB"
+		}
+		detectors = append(detectors, detector{"freezedetect", args})
+	}
+	if o := opts.Crop; o != nil {
+		args := KwArgs{}
+		if o.Limit > 0 {
+			args["limit"] = formatFloat(o.Limit)
+		}
+		if o.Round > 0 {
+			args["round"] = o.Round
+		}
+		detectors = append(detectors, detector{"cropdetect", args})
+	}
+	if len(detectors) == 0 {
+		return QCReport{}, errors.New("no detector selected")
+	}
+
+	video := videoOf(input)
+	var split *Node
+	if len(detectors) > 1 {
+		split = video.Split()
+	}
+	var streams []*Stream
+	for i, d := range detectors {
+		branch := video
+		if split != nil {
+			branch = split.Get(strconv.Itoa(i))
+		}
+		streams = append(streams, branch.Filter(d.filter, nil, d.args))
+	}
+	parser := &qcParser{}
+	err := runWithLogParser(nullOutput(ctx, input.FfmpegPath, streams), parser.parse)
+	if err != nil {
+		return QCReport{}, err
+	}
+	return parser.report(), nil
+}
+
+// AutoCrop detects the black borders of s with cropdetect, and returns s
+// cropped to the detected area. The detection runs with s.Context.
+func (s *Stream) AutoCrop(opts ...CropDetectOptions) (*Stream, error) {
+	AssertType(s.Type, "FilterableStream", "crop")
+	opt := CropDetectOptions{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	report, err := AnalyzeVideo(s.Context, s, QCOptions{Crop: &opt})
+	if err != nil {
+		return nil, err
+	}
+	if report.Crop == nil {
+		return nil, errors.New("no crop detected")
+	}
+	c := report.Crop
+	return videoOf(s).Crop(c.X, c.Y, c.W, c.H), nil
+}
+
+// qcParser parses the logs of the detectors
+//
+//	[blackdetect @ 0x7f9] black_start:0 black_end:1.96 black_duration:1.96
+//	[freezedetect @ 0x7f9] lavfi.freezedetect.freeze_start: 2.3
+//	[freezedetect @ 0x7f9] lavfi.freezedetect.freeze_end: 3.5
+//	[Parsed_cropdetect_2 @ 0x7f9] x1:0 x2:1919 y1:140 ... crop=1920:800:0:140
+type qcParser struct {
+	black       []Interval
+	freeze      []Interval
+	freezeStart time.Duration
+	freezing    bool
+	crop        *CropRect
+	duration    durationParser
+}
+
+func (p *qcParser) parse(line string) {
+	p.duration.parse(line)
+	switch {
+	case strings.Contains(line, "blackdetect"):
+		start, ok1 := logFloat(line, "black_start:")
+		end, ok2 := logFloat(line, "black_end:")
+		if ok1 && ok2 {
+			p.black = append(p.black, Interval{Start: secondsToDuration(start), End: secondsToDuration(end)})
+		}
+	case strings.Contains(line, "freezedetect"):
+		if t, ok := logFloat(line, "freeze_start:"); ok {
+			p.freezeStart, p.freezing = secondsToDuration(t), true
+		} else if t, ok := logFloat(line, "freeze_end:"); ok {
+			p.freeze = append(p.freeze, Interval{Start: p.freezeStart, End: secondsToDuration(t)})
+			p.freezing = false
+		}
+	case strings.Contains(line, "cropdetect"):
+		if v, ok := logValue(line, "crop="); ok {
+			if crop, ok := parseCrop(v); ok {
+				p.crop = crop
+			}
+		}
+	}
+}
+
+func (p *qcParser) report() QCReport {
+	freeze := p.freeze
+	if p.freezing {
+		// still frozen at the end of the input
+		freeze = append(freeze, Interval{Start: p.freezeStart, End: p.duration.duration()})
+	}
+	return QCReport{Black: p.black, Freeze: freeze, Crop: p.crop}
+}
+
+// parseCrop parses the “w:h:x:y“ crop value of cropdetect.
+func parseCrop(s string) (*CropRect, bool) {
+	l := strings.Split(s, ":")
+	if len(l) != 4 {
+		return nil, false
+	}
+	var v [4]int
+	for i := range l {
+		n, err := strconv.Atoi(l[i])
+		if err != nil {
+			return nil, false
+		}
+		v[i] = n
+	}
+	return &CropRect{W: v[0], H: v[1], X: v[2], Y: v[3]}, true
+}
@@ -0,0 +1,31 @@
+package ffmpeg_go
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testQCLog = `  Duration: 00:00:10.00, start: 0.000000, bitrate: 1200 kb/s
+[Parsed_cropdetect_2 @ 0x7f9] x1:0 x2:1919 y1:142 y2:937 w:1920 h:784 x:0 y:148 pts:1 t:0.04 crop=1920:784:0:148
+[freezedetect @ 0x7f9] lavfi.freezedetect.freeze_start: 2.3
+[freezedetect @ 0x7f9] lavfi.freezedetect.freeze_duration: 1.2
+[freezedetect @ 0x7f9] lavfi.freezedetect.freeze_end: 3.5
+[blackdetect @ 0x7f9] black_start:0 black_end:1.96 black_duration:1.96
+[Parsed_cropdetect_2 @ 0x7f9] x1:0 x2:1919 y1:140 y2:939 w:1920 h:800 x:0 y:140 pts:2 t:0.08 crop=1920:800:0:140
+[freezedetect @ 0x7f9] lavfi.freezedetect.freeze_start: 8
+frame=  250 fps=0.0 q=-0.0 Lsize=N/A time=00:00:10.00 bitrate=N/A speed=10x`
+
+func TestQCParser(t *testing.T) {
+	p := &qcParser{}
+	for _, line := range strings.Split(testQCLog, "\n") {
+		p.parse(line)
+	}
+	assert.Equal(t, QCReport{
+		Black:  []Interval{{Start: 0, End: 1960 * time.Millisecond}},
+		Freeze: []Interval{{Start: 2300 * time.Millisecond, End: 3500 * time.Millisecond}, {Start: 8 * time.Second, End: 10 * time.Second}},
+		Crop:   &CropRect{X: 0, Y: 140, W: 1920, H: 800},
+	}, p.report())
+}
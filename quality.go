@@ -0,0 +1,261 @@
+package ffmpeg_go
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Metrics selects the metrics computed by CompareQuality.
+type Metrics struct {
+	PSNR bool
+	SSIM bool
+	// VMAF is skipped if ffmpeg is built without libvmaf.
+	VMAF bool
+	// VMAFModel is passed as “model“ to libvmaf if set.
+	VMAFModel string
+	// FrameRate the distorted video is converted to, by default the frame
+	// rate of the reference is probed if it is a file.
+	FrameRate string
+}
+
+type FrameQuality struct {
+	// Frame is the index of the frame, starting from 0.
+	Frame int
+	PSNR  float64
+	SSIM  float64
+	VMAF  float64
+}
+
+type QualityReport struct {
+	// PSNR, SSIM and VMAF are the averages over all frames.
+	PSNR float64
+	SSIM float64
+	VMAF float64
+	// VMAFSkipped is set if VMAF was selected but libvmaf isn't available.
+	VMAFSkipped bool
+	Frames      []FrameQuality
+}
+
+// CompareQuality compares distorted against reference. The distorted video
+// is converted to the frame rate of the reference and scaled to its size
+// with scale2ref before it is compared, the per frame scores are written to
+// temp files by the metric filters.
+func CompareQuality(ctx context.Context, reference, distorted *Stream, metrics Metrics) (QualityReport, error) {
+	report := QualityReport{}
+	if metrics.VMAF {
+		ok, err := hasFilter(ctx, reference.FfmpegPath, "libvmaf")
+		if err != nil {
+			return report, err
+		}
+		metrics.VMAF, report.VMAFSkipped = ok, !ok
+	}
+	var names []string
+	for _, a := range []struct {
+		enabled bool
+		name    string
+	}{{metrics.PSNR, "psnr"}, {metrics.SSIM, "ssim"}, {metrics.VMAF, "libvmaf"}} {
+		if a.enabled {
+			names = append(names, a.name)
+		}
+	}
+	if len(names) == 0 {
+		return report, nil
+	}
+
+	rate := metrics.FrameRate
+	if rate == "" {
+		rate = probeFrameRate(ctx, reference)
+	}
+	dist, ref := videoOf(distorted), videoOf(reference)
+	if rate != "" {
+		dist = dist.Filter("fps", Args{rate})
+	}
+	scaled := FilterMultiOutput([]*Stream{dist, ref}, "scale2ref", nil)
+	dists, refs := []*Stream{scaled.Get("0")}, []*Stream{scaled.Get("1")}
+	if len(names) > 1 {
+		distSplit, refSplit := dists[0].Split(), refs[0].Split()
+		dists, refs = nil, nil
+		for i := range names {
+			dists = append(dists, distSplit.Get(strconv.Itoa(i)))
+			refs = append(refs, refSplit.Get(strconv.Itoa(i)))
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "ffmpeg_go_quality_")
+	if err != nil {
+		return report, err
+	}
+	defer os.RemoveAll(dir)
+	var outputs []*Stream
+	for i, name := range names {
+		logFile := filepath.Join(dir, name+".log")
+		args := KwArgs{"stats_file": logFile}
+		if name == "libvmaf" {
+			args = KwArgs{"log_path": logFile, "log_fmt": "json"}
+			if metrics.VMAFModel != "" {
+				args["model"] = metrics.VMAFModel
+			}
+		}
+		outputs = append(outputs, Filter([]*Stream{dists[i], refs[i]}, name, nil, args))
+	}
+	parser := &qualityParser{}
+	err = runWithLogParser(nullOutput(ctx, reference.FfmpegPath, outputs), parser.parse)
+	if err != nil {
+		return report, err
+	}
+	report.PSNR, report.SSIM, report.VMAF = parser.psnr, parser.ssim, parser.vmaf
+
+	frames := map[int]*FrameQuality{}
+	for _, name := range names {
+		data, err := ioutil.ReadFile(filepath.Join(dir, name+".log"))
+		if err != nil {
+			return report, err
+		}
+		if name == "libvmaf" {
+			err = parseVMAFLog(data, frames)
+		} else {
+			err = parseStatsFile(name, data, frames)
+		}
+		if err != nil {
+			return report, err
+		}
+	}
+	for _, f := range frames {
+		report.Frames = append(report.Frames, *f)
+	}
+	sort.Slice(report.Frames, func(i, j int) bool {
+		return report.Frames[i].Frame < report.Frames[j].Frame
+	})
+	return report, nil
+}
+
+// hasFilter checks if ffmpeg is built with filter name.
+func hasFilter(ctx context.Context, ffmpegPath, name string) (bool, error) {
+	cmd := exec.CommandContext(ctx, ffmpegPath, "-hide_banner", "-filters")
+	for _, option := range GlobalCommandOptions {
+		option(cmd)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		return false, newCommandError(cmd, err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		// e.g. " ... libvmaf           VV->V      Calculate the VMAF between two video streams."
+		fields := strings.Fields(line)
+		if len(fields) > 1 && fields[1] == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// probeFrameRate returns the frame rate of the first video stream of s if it
+// is a file input, otherwise "".
+func probeFrameRate(ctx context.Context, s *Stream) string {
+	fileName, ok := probeFileOf(s)
+	if !ok {
+		return ""
+	}
+	data, err := ProbeContext(ctx, fileName, ProbeOptions{KwArgs: KwArgs{"select_streams": "v:0"}})
+	if err != nil {
+		return ""
+	}
+	info := struct {
+		Streams []struct {
+			RFrameRate string `json:"r_frame_rate"`
+		} `json:"streams"`
+	}{}
+	if json.Unmarshal([]byte(data), &info) != nil || len(info.Streams) == 0 || info.Streams[0].RFrameRate == "0/0" {
+		return ""
+	}
+	return info.Streams[0].RFrameRate
+}
+
+// qualityParser parses the averages the metric filters log at the end
+//
+//	[Parsed_psnr_3 @ 0x7f9] PSNR y:49.72 u:55.55 v:55.12 average:50.96 min:48.20 max:52.10
+//	[Parsed_ssim_4 @ 0x7f9] SSIM Y:0.995 (23.0) U:0.997 (25.2) V:0.998 (26.9) All:0.996 (23.9)
+//	[Parsed_libvmaf_5 @ 0x7f9] VMAF score: 95.301
+type qualityParser struct {
+	psnr, ssim, vmaf float64
+}
+
+func (p *qualityParser) parse(line string) {
+	switch {
+	case strings.Contains(line, "Parsed_psnr"):
+		if f, ok := logFloat(line, "average:"); ok {
+			p.psnr = f
+		}
+	case strings.Contains(line, "Parsed_ssim"):
+		if f, ok := logFloat(line, "All:"); ok {
+			p.ssim = f
+		}
+	case strings.Contains(line, "Parsed_libvmaf"):
+		if f, ok := logFloat(line, "VMAF score:"); ok {
+			p.vmaf = f
+		}
+	}
+}
+
+func frameOf(frames map[int]*FrameQuality, i int) *FrameQuality {
+	if frames[i] == nil {
+		frames[i] = &FrameQuality{Frame: i}
+	}
+	return frames[i]
+}
+
+// parseStatsFile parses the stats_file of psnr or ssim, with lines like
+//
+//	n:1 mse_avg:0.52 mse_y:0.69 mse_u:0.18 mse_v:0.20 psnr_avg:50.96 psnr_y:49.72 ...
+//	n:1 Y:0.995 U:0.997 V:0.998 All:0.996 (23.9)
+func parseStatsFile(name string, data []byte, frames map[int]*FrameQuality) error {
+	key := "psnr_avg:"
+	if name == "ssim" {
+		key = "All:"
+	}
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		n, ok1 := logValue(line, "n:")
+		f, ok2 := logFloat(line, key)
+		i, err := strconv.Atoi(n)
+		if !ok1 || !ok2 || err != nil {
+			return fmt.Errorf("parse %s stats line %q fail", name, line)
+		}
+		if name == "ssim" {
+			frameOf(frames, i-1).SSIM = f
+		} else {
+			frameOf(frames, i-1).PSNR = f
+		}
+	}
+	return scanner.Err()
+}
+
+// parseVMAFLog parses the json log of libvmaf.
+func parseVMAFLog(data []byte, frames map[int]*FrameQuality) error {
+	log := struct {
+		Frames []struct {
+			FrameNum int                `json:"frameNum"`
+			Metrics  map[string]float64 `json:"metrics"`
+		} `json:"frames"`
+	}{}
+	if err := json.Unmarshal(data, &log); err != nil {
+		return fmt.Errorf("parse vmaf log fail: %w", err)
+	}
+	for _, f := range log.Frames {
+		frameOf(frames, f.FrameNum).VMAF = f.Metrics["vmaf"]
+	}
+	return nil
+}
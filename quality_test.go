@@ -0,0 +1,40 @@
+package ffmpeg_go
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestQualityLogs(t *testing.T) {
+	frames := map[int]*FrameQuality{}
+	err := parseStatsFile("psnr", []byte(`n:1 mse_avg:0.52 mse_y:0.69 mse_u:0.18 mse_v:0.20 psnr_avg:50.96 psnr_y:49.72 psnr_u:55.55 psnr_v:55.12
+n:2 mse_avg:0.00 mse_y:0.00 mse_u:0.00 mse_v:0.00 psnr_avg:inf psnr_y:inf psnr_u:inf psnr_v:inf
+`), frames)
+	assert.Nil(t, err)
+	err = parseStatsFile("ssim", []byte("n:1 Y:0.995 U:0.997 V:0.998 All:0.996 (23.9)\nn:2 Y:1.000 U:1.000 V:1.000 All:1.000 (inf)\n"), frames)
+	assert.Nil(t, err)
+	err = parseVMAFLog([]byte(`{"version": "2.3.1", "frames": [
+		{"frameNum": 0, "metrics": {"integer_adm2": 0.99, "vmaf": 95.3}},
+		{"frameNum": 1, "metrics": {"integer_adm2": 1, "vmaf": 100}}
+	], "pooled_metrics": {"vmaf": {"mean": 97.65}}}`), frames)
+	assert.Nil(t, err)
+	assert.Equal(t, FrameQuality{Frame: 0, PSNR: 50.96, SSIM: 0.996, VMAF: 95.3}, *frames[0])
+	assert.True(t, math.IsInf(frames[1].PSNR, 1))
+	assert.Equal(t, 1.0, frames[1].SSIM)
+
+	err = parseStatsFile("psnr", []byte("garbage\n"), frames)
+	assert.NotNil(t, err)
+}
+
+func TestQualityParser(t *testing.T) {
+	p := &qualityParser{}
+	for _, line := range strings.Split(`[Parsed_psnr_3 @ 0x7f9] PSNR y:49.72 u:55.55 v:55.12 average:50.96 min:48.20 max:52.10
+[Parsed_ssim_4 @ 0x7f9] SSIM Y:0.995 (23.0) U:0.997 (25.2) V:0.998 (26.9) All:0.996 (23.9)
+[Parsed_libvmaf_5 @ 0x7f9] VMAF score: 95.301`, "\n") {
+		p.parse(line)
+	}
+	assert.Equal(t, &qualityParser{psnr: 50.96, ssim: 0.996, vmaf: 95.301}, p)
+}
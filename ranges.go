@@ -0,0 +1,117 @@
+package ffmpeg_go
+
+import (
+	"sort"
+	"strconv"
+	"time"
+)
+
+// KeepRanges cuts the ranges out of s and joins them with concat. If s is an
+// input without selector both video and audio are cut with the same ranges
+// so that they stay in sync. Otherwise only s is cut, as audio if it is
+// selected with "a" and as video else, and the other result is nil. A
+// filtered stream is split into one stream per range first.
+func (s *Stream) KeepRanges(ranges []Interval) (video, audio *Stream) {
+	AssertType(s.Type, "FilterableStream", "keep ranges")
+	ranges = mergeRanges(ranges)
+	if len(ranges) == 0 {
+		panic("no range to keep")
+	}
+	return trimRanges(s, ranges)
+}
+
+// DropRanges removes the ranges from s, it is KeepRanges of the gaps between
+// them. The last gap lasts to the end of s.
+func (s *Stream) DropRanges(ranges []Interval) (video, audio *Stream) {
+	AssertType(s.Type, "FilterableStream", "drop ranges")
+	var keep []Interval
+	start := time.Duration(0)
+	for _, r := range mergeRanges(ranges) {
+		if r.Start > start {
+			keep = append(keep, Interval{Start: start, End: r.Start})
+		}
+		start = r.End
+	}
+	// an interval without end is trimmed to the end of s
+	keep = append(keep, Interval{Start: start})
+	return trimRanges(s, keep)
+}
+
+// mergeRanges sorts ranges and merges overlapping ones, empty ranges are
+// dropped.
+func mergeRanges(ranges []Interval) []Interval {
+	var merged []Interval
+	for _, r := range ranges {
+		if r.End > r.Start {
+			merged = append(merged, r)
+		}
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Start < merged[j].Start })
+	n := 0
+	for _, r := range merged {
+		if n > 0 && r.Start <= merged[n-1].End {
+			if r.End > merged[n-1].End {
+				merged[n-1].End = r.End
+			}
+			continue
+		}
+		merged[n] = r
+		n++
+	}
+	return merged[:n]
+}
+
+func trimRanges(s *Stream, ranges []Interval) (video, audio *Stream) {
+	withVideo, withAudio := mediaOf(s)
+	var split *Node
+	if s.Node.nodeType != "InputNode" && len(ranges) > 1 {
+		// the output of a filter can only be used once
+		if withAudio {
+			split = s.ASplit()
+		} else {
+			split = s.Split()
+		}
+	}
+	var streams []*Stream
+	for i, r := range ranges {
+		trim := KwArgs{"start": formatSeconds(r.Start)}
+		if r.End > 0 {
+			trim["end"] = formatSeconds(r.End)
+		}
+		v, a := videoOf(s), audioOf(s)
+		if split != nil {
+			v = split.Get(strconv.Itoa(i))
+			a = v
+		}
+		if withVideo {
+			streams = append(streams, v.Filter("trim", nil, trim).Filter("setpts", Args{"PTS-STARTPTS"}))
+		}
+		if withAudio {
+			streams = append(streams, a.Filter("atrim", nil, trim).Filter("asetpts", Args{"PTS-STARTPTS"}))
+		}
+	}
+	if len(ranges) == 1 {
+		if withVideo {
+			video = streams[0]
+		}
+		if withAudio {
+			audio = streams[len(streams)-1]
+		}
+		return video, audio
+	}
+	v, a := 0, 0
+	if withVideo {
+		v = 1
+	}
+	if withAudio {
+		a = 1
+	}
+	joined := Concat(streams, KwArgs{"v": v, "a": a}).Node
+	if withVideo && withAudio {
+		return joined.Get("0"), joined.Get("1")
+	}
+	if withVideo {
+		return joined.Get("0"), nil
+	}
+	return nil, joined.Get("0")
+}
@@ -0,0 +1,65 @@
+package ffmpeg_go
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeepRanges(t *testing.T) {
+	video, audio := Input("in.mp4").KeepRanges([]Interval{
+		{Start: 10 * time.Second, End: 20 * time.Second},
+		{Start: 0, End: 5 * time.Second},
+	})
+	args := Output([]*Stream{video, audio}, "out.mp4").GetArgs()
+	assert.Equal(t, []string{"-i", "in.mp4", "-filter_complex",
+		"[0:v]trim=end=5:start=0[s0];[s0]setpts=PTS-STARTPTS[s1];[0:a]atrim=end=5:start=0[s2];[s2]asetpts=PTS-STARTPTS[s3];" +
+			"[0:v]trim=end=20:start=10[s4];[s4]setpts=PTS-STARTPTS[s5];[0:a]atrim=end=20:start=10[s6];[s6]asetpts=PTS-STARTPTS[s7];" +
+			"[s1][s3][s5][s7]concat=a=1:n=2:v=1[s8][s9]",
+		"-map", "[s8]", "-map", "[s9]", "out.mp4"}, args)
+}
+
+func TestDropRanges(t *testing.T) {
+	video, audio := Input("in.mp4").Audio().DropRanges([]Interval{
+		{Start: 5 * time.Second, End: 10 * time.Second},
+		{Start: 8 * time.Second, End: 12 * time.Second},
+	})
+	assert.Nil(t, video)
+	args := audio.Output("out.wav").GetArgs()
+	assert.Equal(t, "[0:a]atrim=end=5:start=0[s0];[s0]asetpts=PTS-STARTPTS[s1];[0:a]atrim=start=12[s2];[s2]asetpts=PTS-STARTPTS[s3];"+
+		"[s1][s3]concat=a=1:n=2:v=0[s4]", args[3])
+
+	video, audio = Input("in.mp4").Video().DropRanges([]Interval{{Start: 0, End: 3 * time.Second}})
+	assert.Nil(t, audio)
+	assert.Equal(t, "[0:v]trim=start=3[s0];[s0]setpts=PTS-STARTPTS[s1]", video.Output("out.mp4").GetArgs()[3])
+}
+
+func TestRangesOfFilteredStream(t *testing.T) {
+	video, audio := Input("in.mp4").Video().Filter("scale", Args{"640", "-2"}).KeepRanges([]Interval{
+		{Start: 0, End: 5 * time.Second},
+		{Start: 10 * time.Second, End: 20 * time.Second},
+	})
+	assert.Nil(t, audio)
+	assert.Equal(t, "[0:v]scale=640:-2[s0];[s0]split=2[s1][s2];"+
+		"[s1]trim=end=5:start=0[s3];[s3]setpts=PTS-STARTPTS[s4];[s2]trim=end=20:start=10[s5];[s5]setpts=PTS-STARTPTS[s6];"+
+		"[s4][s6]concat=a=0:n=2:v=1[s7]", video.Output("out.mp4").GetArgs()[3])
+
+	video, audio = Input("in.mp4").HFlip().DropRanges([]Interval{{Start: 5 * time.Second, End: 10 * time.Second}})
+	assert.Nil(t, audio)
+	assert.Equal(t, "[0]hflip[s0];[s0]split=2[s1][s2];"+
+		"[s1]trim=end=5:start=0[s3];[s3]setpts=PTS-STARTPTS[s4];[s2]trim=start=10[s5];[s5]setpts=PTS-STARTPTS[s6];"+
+		"[s4][s6]concat=a=0:n=2:v=1[s7]", video.Output("out.mp4").GetArgs()[3])
+
+	// an input stream can be trimmed more than once without split
+	_, audio = Input("in.mp4").Get("a:1").KeepRanges([]Interval{{Start: 0, End: time.Second}, {Start: 2 * time.Second, End: 3 * time.Second}})
+	assert.Equal(t, "[0:a:1]atrim=end=1:start=0[s0];[s0]asetpts=PTS-STARTPTS[s1];[0:a:1]atrim=end=3:start=2[s2];[s2]asetpts=PTS-STARTPTS[s3];"+
+		"[s1][s3]concat=a=1:n=2:v=0[s4]", audio.Output("out.wav").GetArgs()[3])
+}
+
+func TestMergeRanges(t *testing.T) {
+	assert.Equal(t, []Interval{{Start: 0, End: 3}, {Start: 4, End: 9}}, mergeRanges([]Interval{
+		{Start: 4, End: 6}, {Start: 0, End: 3}, {Start: 5, End: 9}, {Start: 7, End: 7},
+	}))
+	assert.Panics(t, func() { Input("in.mp4").KeepRanges(nil) })
+}
@@ -2,6 +2,7 @@ package ffmpeg_go
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -9,14 +10,22 @@ import (
 	"os/exec"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
-func getInputArgs(node *Node) []string {
+func getInputArgs(node *Node, fds map[int]int, files map[int]string) []string {
 	var args []string
 	if node.name == "input" {
 		kwargs := node.kwargs.Copy()
 		filename := kwargs.PopString("filename")
+		if fd, ok := fds[node.Hash()]; ok {
+			filename = pipeFilename(fd)
+		} else if f, ok := files[node.Hash()]; ok {
+			filename = f
+		} else if node.source != nil {
+			filename = node.source.filename()
+		}
 		format := kwargs.PopString("format")
 		videoSize := kwargs.PopString("video_size")
 		if format != "" {
@@ -113,7 +122,7 @@ func _getGlobalArgs(node *Node) []string {
 	return node.args
 }
 
-func _getOutputArgs(node *Node, streamNameMap map[string]string) []string {
+func _getOutputArgs(node *Node, streamNameMap map[string]string, fds map[int]int) []string {
 	if node.name != "output" {
 		panic("Unsupported output node")
 	}
@@ -130,6 +139,9 @@ func _getOutputArgs(node *Node, streamNameMap map[string]string) []string {
 	kwargs := node.kwargs.Copy()
 
 	filename := kwargs.PopString("filename")
+	if fd, ok := fds[node.Hash()]; ok {
+		filename = pipeFilename(fd)
+	}
 	if kwargs.HasKey("format") {
 		args = append(args, "-f", kwargs.PopString("format"))
 	}
@@ -148,15 +160,35 @@ func _getOutputArgs(node *Node, streamNameMap map[string]string) []string {
 	return args
 }
 
-func (s *Stream) GetArgs() []string {
-	var args []string
+func (s *Stream) topSort() ([]DagNode, map[int]map[Label][]NodeInfo, error) {
 	nodes := getStreamSpecNodes([]*Stream{s})
 	var dagNodes []DagNode
-	streamNameMap := map[string]string{}
 	for i := range nodes {
 		dagNodes = append(dagNodes, nodes[i])
 	}
-	sorted, outGoingMap, err := TopSort(dagNodes)
+	return TopSort(dagNodes)
+}
+
+// nodesOfType returns the nodes of given type in the graph of s, in the
+// same order as they appear on the command line.
+func (s *Stream) nodesOfType(nodeType string) []*Node {
+	sorted, _, err := s.topSort()
+	if err != nil {
+		panic(err)
+	}
+	var nodes []*Node
+	for _, n := range sorted {
+		if n.(*Node).nodeType == nodeType {
+			nodes = append(nodes, n.(*Node))
+		}
+	}
+	return nodes
+}
+
+func (s *Stream) GetArgs() []string {
+	var args []string
+	streamNameMap := map[string]string{}
+	sorted, outGoingMap, err := s.topSort()
 	if err != nil {
 		panic(err)
 	}
@@ -177,9 +209,12 @@ func (s *Stream) GetArgs() []string {
 			filterNodes = append(filterNodes, n)
 		}
 	}
+	fds := s.allocateFds(sorted)
+	// the downloaded sources of a run
+	files, _ := s.Context.Value("source_files").(map[int]string)
 	// input args from inputNodes
 	for _, n := range inputNodes {
-		args = append(args, getInputArgs(n)...)
+		args = append(args, getInputArgs(n, fds, files)...)
 	}
 	// filter args from filterNodes
 	filterArgs := _getFilterArg(filterNodes, outGoingMap, streamNameMap)
@@ -188,7 +223,7 @@ func (s *Stream) GetArgs() []string {
 	}
 	// output args from outputNodes
 	for _, n := range outputNodes {
-		args = append(args, _getOutputArgs(n, streamNameMap)...)
+		args = append(args, _getOutputArgs(n, streamNameMap, fds)...)
 	}
 	// global args with outputNodes
 	for _, n := range globalNodes {
@@ -202,7 +237,8 @@ func (s *Stream) GetArgs() []string {
 
 func (s *Stream) WithTimeout(timeOut time.Duration) *Stream {
 	if timeOut > 0 {
-		s.Context, _ = context.WithTimeout(s.Context, timeOut)
+		ctx, cancel := context.WithTimeout(s.Context, timeOut)
+		s.Context = context.WithValue(ctx, "cancel", cancel)
 	}
 	return s
 }
@@ -253,7 +289,17 @@ func (s *Stream) Silent(isSilent bool) *Stream {
 	LogCompiledCommand = !isSilent
 	return s
 }
+// Compile returns the ffmpeg command of the stream. Go readers and writers
+// on extra file descriptors and seekable remote sources are bound by Run
+// only, Compile panics for a graph with them.
 func (s *Stream) Compile(options ...CompilationOption) *exec.Cmd {
+	if s.bindsAtRun() {
+		panic("the pipes and downloaded sources of the graph are bound by Run only")
+	}
+	return s.compile(options...)
+}
+
+func (s *Stream) compile(options ...CompilationOption) *exec.Cmd {
 	args := s.GetArgs()
 	cmd := exec.CommandContext(s.Context, s.FfmpegPath, args...)
 	if a, ok := s.Context.Value("Stdin").(io.Reader); ok {
@@ -265,25 +311,178 @@ func (s *Stream) Compile(options ...CompilationOption) *exec.Cmd {
 	if a, ok := s.Context.Value("Stderr").(io.Writer); ok {
 		cmd.Stderr = a
 	}
+	s.bindSources(cmd)
 	for _, option := range GlobalCommandOptions {
 		option(cmd)
 	}
-  if LogCompiledCommand {
+	for _, option := range options {
+		option(s, cmd)
+	}
+	if LogCompiledCommand {
 		log.Printf("compiled command: ffmpeg %s\n", strings.Join(args, " "))
 	}
 	return cmd
 }
 
+func withRunHook(ctx context.Context, hooks ...*RunHook) context.Context {
+	return context.WithValue(ctx, "run_hooks", append(getRunHooks(ctx), hooks...))
+}
+
+func getRunHooks(ctx context.Context) []*RunHook {
+	hooks, _ := ctx.Value("run_hooks").([]*RunHook)
+	return hooks[:len(hooks):len(hooks)]
+}
+
 func (s *Stream) Run(options ...CompilationOption) error {
-	if s.Context.Value("run_hook") != nil {
-		hook := s.Context.Value("run_hook").(*RunHook)
-		go hook.f()
-		defer func() {
-			if hook.closer != nil {
-				_ = hook.closer.Close()
+	return s.startAndWait(options, nil)
+}
+
+// startAndWait compiles the command and starts it together with the run
+// hooks of the stream. The process is killed as soon as a hook fails, and
+// the errors of the process and of all hooks are joined into the returned
+// error.
+func (s *Stream) startAndWait(options []CompilationOption, started func(cmd *exec.Cmd) error) error {
+	if cancel, ok := s.Context.Value("cancel").(context.CancelFunc); ok {
+		defer cancel()
+	}
+	files, cleanup, err := s.downloadSources()
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	run := *s
+	run.Context = context.WithValue(s.Context, "source_files", files)
+	cmd := run.compile(options...)
+	pipeHooks, err := run.bindPipes(cmd)
+	if err != nil {
+		return err
+	}
+	hooks := append(getRunHooks(s.Context), pipeHooks...)
+	results := make([]chan error, len(hooks))
+	failed := make(chan struct{})
+	var failOnce sync.Once
+	for i, hook := range hooks {
+		results[i] = make(chan error, 1)
+		go func(hook *RunHook, result chan<- error) {
+			err := hook.f()
+			if err != nil {
+				failOnce.Do(func() { close(failed) })
+			}
+			result <- err
+			if hook.feed {
+				// ffmpeg sees the end of the input only after the result
+				// is recorded
+				_ = hook.closer.CloseWithError(err)
+			}
+		}(hook, results[i])
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		err = newCommandError(cmd, err)
+	}
+	for _, hook := range hooks {
+		for _, f := range hook.childFiles {
+			_ = f.Close()
+		}
+	}
+	if err == nil && started != nil {
+		if err = started(cmd); err != nil {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+		}
+	}
+	if err == nil {
+		exited := make(chan struct{})
+		go func() {
+			select {
+			case <-failed:
+				_ = cmd.Process.Kill()
+			case <-exited:
 			}
-			<-hook.done
 		}()
+		if err = cmd.Wait(); err != nil {
+			err = newCommandError(cmd, err)
+		}
+		close(exited)
+	}
+
+	errs := []error{err}
+	for i, hook := range hooks {
+		if hook.drain {
+			// the output must be passed on before the sinks are closed
+			errs = append(errs, <-results[i])
+		}
+	}
+	for _, hook := range hooks {
+		if hook.closer != nil {
+			// a nil err closes with EOF, otherwise the hook sees the failure
+			_ = hook.closer.CloseWithError(err)
+		}
+	}
+	for i, hook := range hooks {
+		switch {
+		case hook.feed:
+			select {
+			case err := <-results[i]:
+				errs = append(errs, err)
+			default:
+				// the reader still blocks, its copy fails once it returns
+			}
+		case !hook.drain:
+			errs = append(errs, <-results[i])
+		}
+	}
+	return joinErrors(errs...)
+}
+
+// multiError is the error returned when both ffmpeg and a run hook failed.
+type multiError []error
+
+func (m multiError) Error() string {
+	var s []string
+	for _, err := range m {
+		s = append(s, err.Error())
+	}
+	return strings.Join(s, "; ")
+}
+
+func (m multiError) Unwrap() []error {
+	return m
+}
+
+// Is and As look into all errors, errors.Is and errors.As only follow
+// Unwrap() []error since go 1.20.
+func (m multiError) Is(target error) bool {
+	for _, err := range m {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m multiError) As(target interface{}) bool {
+	for _, err := range m {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinErrors(errs ...error) error {
+	var m multiError
+	for _, err := range errs {
+		if err != nil {
+			m = append(m, err)
+		}
+	}
+	switch len(m) {
+	case 0:
+		return nil
+	case 1:
+		return m[0]
 	}
-	return s.Compile(options...).Run()
+	return m
 }
@@ -122,25 +122,21 @@ func (s *Stream) RunLinux() error {
 		}
 	}
 
-	cmd := s.Compile()
-	err = cmd.Start()
-	if err != nil {
-		return err
-	}
-	if share > 0 || quota > 0 {
-		err = writeCGroupFile(rootCpuPath, procsFile, strconv.Itoa(cmd.Process.Pid))
-		if err != nil {
-			return err
+	return s.startAndWait(nil, func(cmd *exec.Cmd) error {
+		if share > 0 || quota > 0 {
+			err := writeCGroupFile(rootCpuPath, procsFile, strconv.Itoa(cmd.Process.Pid))
+			if err != nil {
+				return err
+			}
 		}
-	}
-	if a.cpuset != "" && a.memset != "" {
-		err = writeCGroupFile(rootCpuSetPath, procsFile, strconv.Itoa(cmd.Process.Pid))
-		if err != nil {
-			return err
+		if a.cpuset != "" && a.memset != "" {
+			err := writeCGroupFile(rootCpuSetPath, procsFile, strconv.Itoa(cmd.Process.Pid))
+			if err != nil {
+				return err
+			}
 		}
-	}
-
-	return cmd.Wait()
+		return nil
+	})
 }
 
 // SeparateProcessGroup ensures that the command is run in a separate process
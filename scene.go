@@ -0,0 +1,89 @@
+package ffmpeg_go
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SceneCut struct {
+	Time  time.Duration
+	Score float64
+	// Thumbnail is the jpeg of the first frame of the scene, only read if
+	// SceneOptions.Thumbnails is set.
+	Thumbnail []byte
+}
+
+type SceneOptions struct {
+	// Thumbnails reads the thumbnails in further runs, so the inputs must be
+	// files.
+	Thumbnails bool
+	// ThumbnailKwArgs are passed to ReadFrameAsJpeg, e.g. {"vf": "scale=320:-1"}.
+	ThumbnailKwArgs KwArgs
+}
+
+// DetectScenes returns the frames of input whose scene change score is
+// greater than threshold (0-1, 0.3 to 0.4 is usually a good value).
+//
+// It runs “select='gt(scene,threshold)',metadata=print“ to the null muxer
+// and parses the printed frames from stderr.
+func DetectScenes(ctx context.Context, input *Stream, threshold float64, opts ...SceneOptions) ([]SceneCut, error) {
+	opt := SceneOptions{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	if opt.Thumbnails && !readAgain(input) {
+		return nil, errors.New("scene thumbnails need file inputs, the input is read again for them")
+	}
+	selected := videoOf(input).
+		Filter("select", Args{"gt(scene," + strconv.FormatFloat(threshold, 'f', -1, 64) + ")"}).
+		Filter("metadata", Args{"print"})
+	parser := &sceneParser{}
+	err := runWithLogParser(nullOutput(ctx, input.FfmpegPath, []*Stream{selected}), parser.parse)
+	if err != nil {
+		return nil, err
+	}
+	if opt.Thumbnails {
+		for i := range parser.cuts {
+			parser.cuts[i].Thumbnail, err = input.ReadFrameAsJpeg(ctx, parser.cuts[i].Time, opt.ThumbnailKwArgs)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return parser.cuts, nil
+}
+
+// readAgain tells if the inputs of s can be read by another run: files, but
+// not pipes, readers or remote sources.
+func readAgain(s *Stream) bool {
+	for _, n := range s.nodesOfType("InputNode") {
+		filename := n.kwargs.GetString("filename")
+		if n.source != nil || n.pipe != nil || filename == "-" || strings.HasPrefix(filename, "pipe:") {
+			return false
+		}
+	}
+	return true
+}
+
+// sceneParser parses the output of the metadata filter, which prints
+//
+//	[Parsed_metadata_1 @ 0x7f9] frame:0    pts:35      pts_time:1.4
+//	[Parsed_metadata_1 @ 0x7f9] lavfi.scene_score=0.521399
+type sceneParser struct {
+	cuts []SceneCut
+	time float64
+}
+
+func (p *sceneParser) parse(line string) {
+	if !strings.Contains(line, "Parsed_metadata") {
+		return
+	}
+	if t, ok := logFloat(line, "pts_time:"); ok {
+		p.time = t
+	} else if score, ok := logFloat(line, "lavfi.scene_score="); ok {
+		p.cuts = append(p.cuts, SceneCut{Time: secondsToDuration(p.time), Score: score})
+	}
+}
@@ -0,0 +1,25 @@
+package ffmpeg_go
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSceneParser(t *testing.T) {
+	log := `[Parsed_metadata_1 @ 0x7f9] frame:0    pts:35      pts_time:1.4
+[Parsed_metadata_1 @ 0x7f9] lavfi.scene_score=0.521399
+frame=   10 fps=0.0 q=-0.0 size=N/A time=00:00:02.00 bitrate=N/A speed=4x
+[Parsed_metadata_1 @ 0x7f9] frame:1    pts:125     pts_time:5
+[Parsed_metadata_1 @ 0x7f9] lavfi.scene_score=1.000000`
+	p := &sceneParser{}
+	for _, line := range strings.Split(log, "\n") {
+		p.parse(line)
+	}
+	assert.Equal(t, []SceneCut{
+		{Time: 1400 * time.Millisecond, Score: 0.521399},
+		{Time: 5 * time.Second, Score: 1},
+	}, p.cuts)
+}
@@ -0,0 +1,198 @@
+package ffmpeg_go
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SegmentOptions struct {
+	// Duration is the target segment length (“segment_time“), segments are
+	// cut on the next keyframe after it.
+	Duration time.Duration
+	// Format is the muxer of each segment (“segment_format“), e.g. "mpegts".
+	Format string
+	// Dir is the directory the segments are written to.
+	Dir string
+	// Pattern is the segment file name in Dir, default "segment_%05d" with an
+	// extension matching Format.
+	Pattern string
+	// KwArgs are passed to the output verbatim, e.g. {"c": "copy"}.
+	KwArgs KwArgs
+	// OnSegment is called with each segment as soon as ffmpeg closed it.
+	OnSegment func(segment Segment)
+}
+
+type Segment struct {
+	Index    int
+	Path     string
+	Start    time.Duration
+	Duration time.Duration
+}
+
+var segmentExtensions = map[string]string{
+	"mpegts":   ".ts",
+	"matroska": ".mkv",
+	"mp4":      ".mp4",
+	"adts":     ".aac",
+}
+
+func segmentPattern(opts SegmentOptions) string {
+	if opts.Pattern != "" {
+		return opts.Pattern
+	}
+	ext, ok := segmentExtensions[opts.Format]
+	if !ok && opts.Format != "" {
+		ext = "." + opts.Format
+	} else if !ok {
+		ext = ".ts"
+	}
+	return "segment_%05d" + ext
+}
+
+// Segments cuts s with the segment muxer and runs it. The segment list is
+// written to a temp file as csv, which is tailed to call opts.OnSegment while
+// ffmpeg is running.
+//
+// ffmpeg writes the list to “<list>.tmp“ and renames it at the end when
+// outputting to files, so whichever of both shows up first is tailed.
+func (s *Stream) Segments(ctx context.Context, opts SegmentOptions) error {
+	AssertType(s.Type, "FilterableStream", "segment")
+	if opts.Dir == "" {
+		return errors.New("segment dir must be provided")
+	}
+	listDir, err := ioutil.TempDir("", "ffmpeg_go_segments_")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(listDir)
+	list := filepath.Join(listDir, "segments.csv")
+
+	args := KwArgs{
+		"format":            "segment",
+		"segment_list":      list,
+		"segment_list_type": "csv",
+	}
+	if opts.Duration > 0 {
+		args["segment_time"] = strconv.FormatFloat(opts.Duration.Seconds(), 'f', -1, 64)
+	}
+	if opts.Format != "" {
+		args["segment_format"] = opts.Format
+	}
+	o := OutputContext(ctx, []*Stream{s}, filepath.Join(opts.Dir, segmentPattern(opts)), opts.KwArgs, args)
+	o.FfmpegPath = s.FfmpegPath
+
+	done := make(chan struct{})
+	tailErr := make(chan error, 1)
+	go func() {
+		tailErr <- tailSegmentList(list, opts.Dir, done, opts.OnSegment)
+	}()
+	err = o.Run()
+	close(done)
+	return joinErrors(err, <-tailErr)
+}
+
+// tailSegmentList follows the csv segment list until done is closed and all
+// lines are read.
+func tailSegmentList(listFile, dir string, done <-chan struct{}, onSegment func(Segment)) error {
+	f, err := waitSegmentList(listFile, done)
+	if err != nil || f == nil {
+		return err
+	}
+	defer f.Close()
+	reader := bufio.NewReader(f)
+	line, index, finished := "", 0, false
+	for {
+		part, err := reader.ReadString('\n')
+		line += part
+		if err == nil {
+			segment, err := parseSegmentListLine(strings.TrimRight(line, "\r\n"), dir)
+			if err != nil {
+				return err
+			}
+			segment.Index = index
+			index++
+			if onSegment != nil {
+				onSegment(segment)
+			}
+			line = ""
+			continue
+		}
+		if err != io.EOF {
+			return err
+		}
+		if finished {
+			return nil
+		}
+		select {
+		case <-done:
+			// read once more, ffmpeg may have written since the last EOF
+			finished = true
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// waitSegmentList opens the list once ffmpeg created it, it returns nil if
+// ffmpeg exited before.
+func waitSegmentList(listFile string, done <-chan struct{}) (*os.File, error) {
+	finished := false
+	for {
+		for _, name := range []string{listFile + ".tmp", listFile} {
+			f, err := os.Open(name)
+			if err == nil {
+				return f, nil
+			}
+			if !os.IsNotExist(err) {
+				return nil, err
+			}
+		}
+		if finished {
+			return nil, nil
+		}
+		select {
+		case <-done:
+			finished = true
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// parseSegmentListLine parses a “segment_list_type=csv“ line, which is
+// “filename,start,end“ with times in seconds.
+func parseSegmentListLine(line, dir string) (Segment, error) {
+	fields, err := csv.NewReader(strings.NewReader(line)).Read()
+	if err != nil {
+		return Segment{}, fmt.Errorf("parse segment list line %q fail: %w", line, err)
+	}
+	if len(fields) != 3 {
+		return Segment{}, fmt.Errorf("parse segment list line %q fail: expected 3 fields", line)
+	}
+	start, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return Segment{}, fmt.Errorf("parse segment list line %q fail: %w", line, err)
+	}
+	end, err := strconv.ParseFloat(fields[2], 64)
+	if err != nil {
+		return Segment{}, fmt.Errorf("parse segment list line %q fail: %w", line, err)
+	}
+	return Segment{
+		Path:     filepath.Join(dir, fields[0]),
+		Start:    secondsToDuration(start),
+		Duration: secondsToDuration(end - start),
+	}, nil
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Round(seconds * float64(time.Second)))
+}
@@ -0,0 +1,38 @@
+package ffmpeg_go
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegments(t *testing.T) {
+	ffmpeg := fakeFfmpeg(t, `
+while [ $# -gt 1 ]; do
+	if [ "$1" = -segment_list ]; then list=$2; fi
+	shift
+done
+printf 'segment_00000.ts,0.000000,2.000000\n' >> "$list.tmp"
+sleep 0.2
+printf 'segment_00001.ts,2.000000,3.' >> "$list.tmp"
+sleep 0.2
+printf '500000\n' >> "$list.tmp"
+mv "$list.tmp" "$list"`)
+	var segments []Segment
+	err := Input("dummy.mp4").SetFfmpegPath(ffmpeg).Segments(context.Background(), SegmentOptions{
+		Duration: 2 * time.Second,
+		Format:   "mpegts",
+		Dir:      "out",
+		OnSegment: func(segment Segment) {
+			segments = append(segments, segment)
+		},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []Segment{
+		{Index: 0, Path: filepath.Join("out", "segment_00000.ts"), Start: 0, Duration: 2 * time.Second},
+		{Index: 1, Path: filepath.Join("out", "segment_00001.ts"), Start: 2 * time.Second, Duration: 1500 * time.Millisecond},
+	}, segments)
+}
@@ -0,0 +1,33 @@
+package ffmpeg_go
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSegmentListLine(t *testing.T) {
+	segment, err := parseSegmentListLine("segment_00001.ts,2.000000,3.500000", "out")
+	assert.Nil(t, err)
+	assert.Equal(t, Segment{
+		Path:     filepath.Join("out", "segment_00001.ts"),
+		Start:    2 * time.Second,
+		Duration: 1500 * time.Millisecond,
+	}, segment)
+
+	segment, err = parseSegmentListLine(`"a,b.ts",0.1,0.2`, "")
+	assert.Nil(t, err)
+	assert.Equal(t, "a,b.ts", segment.Path)
+
+	_, err = parseSegmentListLine("segment_00001.ts,2.0", "out")
+	assert.NotNil(t, err)
+}
+
+func TestSegmentPattern(t *testing.T) {
+	assert.Equal(t, "segment_%05d.ts", segmentPattern(SegmentOptions{}))
+	assert.Equal(t, "segment_%05d.mkv", segmentPattern(SegmentOptions{Format: "matroska"}))
+	assert.Equal(t, "segment_%05d.webm", segmentPattern(SegmentOptions{Format: "webm"}))
+	assert.Equal(t, "%d.ts", segmentPattern(SegmentOptions{Format: "webm", Pattern: "%d.ts"}))
+}
@@ -0,0 +1,168 @@
+package ffmpeg_go
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DetectSilence returns the intervals of input whose audio is below noiseDB
+// (e.g. -30) for at least minDuration, using the silencedetect filter.
+func DetectSilence(ctx context.Context, input *Stream, noiseDB float64, minDuration time.Duration) ([]Interval, error) {
+	silences, _, err := detectSilence(ctx, input, noiseDB, minDuration)
+	return silences, err
+}
+
+// detectSilence also returns the duration of input, a silence lasting until
+// the end is closed with it.
+func detectSilence(ctx context.Context, input *Stream, noiseDB float64, minDuration time.Duration) ([]Interval, time.Duration, error) {
+	detected := audioOf(input).Filter("silencedetect", nil, KwArgs{
+		"n": strconv.FormatFloat(noiseDB, 'f', -1, 64) + "dB",
+		"d": formatSeconds(minDuration),
+	})
+	parser := &silenceParser{}
+	err := runWithLogParser(nullOutput(ctx, input.FfmpegPath, []*Stream{detected}), parser.parse)
+	if err != nil {
+		return nil, 0, err
+	}
+	total := parser.duration.duration()
+	if parser.open {
+		parser.silences = append(parser.silences, Interval{Start: parser.start, End: total})
+	}
+	return parser.silences, total, nil
+}
+
+// silenceParser parses the output of the silencedetect filter, which logs
+//
+//	[silencedetect @ 0x7f9] silence_start: 1.234
+//	[silencedetect @ 0x7f9] silence_end: 3.456 | silence_duration: 2.222
+type silenceParser struct {
+	silences []Interval
+	start    time.Duration
+	open     bool
+	duration durationParser
+}
+
+func (p *silenceParser) parse(line string) {
+	p.duration.parse(line)
+	if !strings.Contains(line, "silencedetect") {
+		return
+	}
+	if t, ok := logFloat(line, "silence_start:"); ok {
+		p.start, p.open = secondsToDuration(t), true
+	} else if t, ok := logFloat(line, "silence_end:"); ok {
+		p.silences = append(p.silences, Interval{Start: p.start, End: secondsToDuration(t)})
+		p.open = false
+	}
+}
+
+type SilenceSplitOptions struct {
+	// NoiseDB and MinSilence are passed to DetectSilence, default -30dB and
+	// 300ms.
+	NoiseDB    float64
+	MinSilence time.Duration
+	// Format of the chunks, default "wav".
+	Format string
+	// KwArgs are passed to every chunk output, e.g. {"ar": 16000, "ac": 1}.
+	KwArgs KwArgs
+}
+
+type AudioChunk struct {
+	Interval
+	// Path is set when the chunks are written to a dir, Data otherwise.
+	Path string
+	Data []byte
+}
+
+// SplitOnSilence cuts the audio of input into chunks of at most maxChunk,
+// preferring to cut in the middle of silences, so that speech isn't split.
+//
+// The silences are detected first, then all chunks are cut in one ffmpeg run
+// with an asplit and one atrim output per chunk. The chunks are written to dir,
+// or kept in memory if dir is empty.
+func SplitOnSilence(ctx context.Context, input *Stream, maxChunk time.Duration, dir string, opts ...SilenceSplitOptions) ([]AudioChunk, error) {
+	opt := SilenceSplitOptions{NoiseDB: -30, MinSilence: 300 * time.Millisecond, Format: "wav"}
+	if len(opts) > 0 {
+		opt = opts[0]
+		if opt.NoiseDB == 0 {
+			opt.NoiseDB = -30
+		}
+		if opt.MinSilence == 0 {
+			opt.MinSilence = 300 * time.Millisecond
+		}
+		if opt.Format == "" {
+			opt.Format = "wav"
+		}
+	}
+	if maxChunk <= 0 {
+		return nil, errors.New("maxChunk must be positive")
+	}
+	silences, total, err := detectSilence(ctx, input, opt.NoiseDB, opt.MinSilence)
+	if err != nil {
+		return nil, err
+	}
+	if total <= 0 {
+		return nil, errors.New("can't find duration of input")
+	}
+
+	chunks := silenceChunks(silences, total, maxChunk)
+	split := audioOf(input).ASplit()
+	var outputs []*Stream
+	for i := range chunks {
+		trimmed := split.Get(strconv.Itoa(i)).
+			Filter("atrim", nil, KwArgs{"start": formatSeconds(chunks[i].Start), "end": formatSeconds(chunks[i].End)}).
+			Filter("asetpts", Args{"PTS-STARTPTS"})
+		args := KwArgs{"format": opt.Format}
+		if dir != "" {
+			chunks[i].Path = filepath.Join(dir, fmt.Sprintf("chunk_%03d.%s", i, opt.Format))
+			outputs = append(outputs, OutputContext(ctx, []*Stream{trimmed}, chunks[i].Path, opt.KwArgs, args))
+		} else {
+			outputs = append(outputs, OutputWriter([]*Stream{trimmed}, &chunkWriter{chunk: &chunks[i]}, opt.KwArgs, args))
+		}
+	}
+	out := MergeOutputs(outputs...)
+	out.Context = ctx
+	out.FfmpegPath = input.FfmpegPath
+	err = runWithLogParser(out.OverWriteOutput(), nil)
+	if err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
+
+// chunkWriter collects the data of an in memory chunk.
+type chunkWriter struct {
+	chunk *AudioChunk
+	buf   bytes.Buffer
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	n, err := w.buf.Write(p)
+	w.chunk.Data = w.buf.Bytes()
+	return n, err
+}
+
+// silenceChunks splits [0, total) into chunks of at most maxChunk. Each chunk
+// ends in the middle of the last silence that fits, or at maxChunk if there is
+// none.
+func silenceChunks(silences []Interval, total, maxChunk time.Duration) []AudioChunk {
+	var chunks []AudioChunk
+	start := time.Duration(0)
+	for total-start > maxChunk {
+		end := start + maxChunk
+		for _, silence := range silences {
+			mid := silence.Start + silence.Duration()/2
+			if mid > start && mid <= start+maxChunk {
+				end = mid
+			}
+		}
+		chunks = append(chunks, AudioChunk{Interval: Interval{Start: start, End: end}})
+		start = end
+	}
+	return append(chunks, AudioChunk{Interval: Interval{Start: start, End: total}})
+}
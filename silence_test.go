@@ -0,0 +1,44 @@
+package ffmpeg_go
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSilenceParser(t *testing.T) {
+	log := `  Duration: 00:00:10.00, start: 0.000000, bitrate: 128 kb/s
+[silencedetect @ 0x7f9] silence_start: 1.5
+[silencedetect @ 0x7f9] silence_end: 2.5 | silence_duration: 1
+size=N/A time=00:00:05.00 bitrate=N/A speed= 100x
+[silencedetect @ 0x7f9] silence_start: 8
+size=N/A time=00:00:09.50 bitrate=N/A speed= 100x`
+	p := &silenceParser{}
+	for _, line := range strings.Split(log, "\n") {
+		p.parse(line)
+	}
+	assert.Equal(t, []Interval{{Start: 1500 * time.Millisecond, End: 2500 * time.Millisecond}}, p.silences)
+	assert.True(t, p.open)
+	assert.Equal(t, 8*time.Second, p.start)
+	assert.Equal(t, 9500*time.Millisecond, p.duration.duration())
+}
+
+func TestSilenceChunks(t *testing.T) {
+	s := time.Second
+	silences := []Interval{{Start: 3 * s, End: 4 * s}, {Start: 6 * s, End: 7 * s}, {Start: 20 * s, End: 21 * s}}
+	chunks := silenceChunks(silences, 25*s, 10*s)
+	var intervals []Interval
+	for _, c := range chunks {
+		intervals = append(intervals, c.Interval)
+	}
+	assert.Equal(t, []Interval{
+		{Start: 0, End: 6500 * time.Millisecond},
+		{Start: 6500 * time.Millisecond, End: 16500 * time.Millisecond},
+		{Start: 16500 * time.Millisecond, End: 25 * s},
+	}, intervals)
+
+	chunks = silenceChunks(nil, 5*s, 10*s)
+	assert.Equal(t, []AudioChunk{{Interval: Interval{Start: 0, End: 5 * s}}}, chunks)
+}
@@ -0,0 +1,201 @@
+package ffmpeg_go
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// SourceOpener opens the object at url for reading. options are the
+// “source_options“ kwargs passed to Input, plus “aws_config“ if given.
+type SourceOpener func(ctx context.Context, url string, options KwArgs) (io.ReadCloser, error)
+
+var (
+	sourceOpenersLock sync.RWMutex
+	sourceOpeners     = map[string]SourceOpener{
+		"s3": openS3Source,
+	}
+)
+
+// RegisterSource makes Input read urls of the given scheme (e.g. "s3") with
+// opener instead of passing them to ffmpeg.
+func RegisterSource(scheme string, opener SourceOpener) {
+	sourceOpenersLock.Lock()
+	defer sourceOpenersLock.Unlock()
+	sourceOpeners[scheme] = opener
+}
+
+func getSourceOpener(filename string) SourceOpener {
+	i := strings.Index(filename, "://")
+	if i <= 0 {
+		return nil
+	}
+	sourceOpenersLock.RLock()
+	defer sourceOpenersLock.RUnlock()
+	return sourceOpeners[filename[:i]]
+}
+
+type remoteSource struct {
+	url      string
+	open     SourceOpener
+	options  KwArgs
+	seekable bool
+	// err is an invalid source option, it is returned by Run
+	err error
+}
+
+// newRemoteSource pops the source related kwargs from args, it returns nil
+// if filename has no registered scheme.
+func newRemoteSource(filename string, args KwArgs) *remoteSource {
+	open := getSourceOpener(filename)
+	if open == nil {
+		return nil
+	}
+	options := KwArgs{}
+	if a, ok := args.PopDefault("source_options", KwArgs{}).(KwArgs); ok {
+		options = a.Copy()
+	}
+	if args.HasKey("aws_config") {
+		options["aws_config"] = args.PopDefault("aws_config", nil)
+	}
+	source := &remoteSource{
+		url:     filename,
+		open:    open,
+		options: options,
+	}
+	seekable := args.PopDefault("seekable", false)
+	if v, ok := seekable.(bool); ok {
+		source.seekable = v
+	} else {
+		source.err = fmt.Errorf("invalid seekable %v of %s, must be a bool", seekable, filename)
+	}
+	return source
+}
+
+// filename is the input of the source outside of Run, a seekable source is
+// downloaded to a temp file of the run.
+func (r *remoteSource) filename() string {
+	if r.seekable {
+		return r.url
+	}
+	return "pipe:"
+}
+
+// download copies the source to a new temp file and returns its path.
+func (r *remoteSource) download(ctx context.Context) (string, error) {
+	rc, err := r.open(ctx, r.url, r.options)
+	if err != nil {
+		return "", fmt.Errorf("open %s fail: %w", r.url, err)
+	}
+	defer rc.Close()
+	// keep the extension, ffmpeg uses it to guess the format
+	f, err := os.CreateTemp("", "ffmpeg_go_*"+path.Ext(r.url))
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(f, rc)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+		return "", fmt.Errorf("download %s fail: %w", r.url, err)
+	}
+	return f.Name(), nil
+}
+
+// sourceReader opens the source on first read, so opening fails the
+// ffmpeg command like any other stdin error.
+type sourceReader struct {
+	ctx    context.Context
+	source *remoteSource
+	rc     io.ReadCloser
+}
+
+func (r *sourceReader) Read(p []byte) (int, error) {
+	if r.rc == nil {
+		rc, err := r.source.open(r.ctx, r.source.url, r.source.options)
+		if err != nil {
+			return 0, fmt.Errorf("open %s fail: %w", r.source.url, err)
+		}
+		r.rc = rc
+	}
+	n, err := r.rc.Read(p)
+	if err != nil {
+		_ = r.rc.Close()
+	}
+	return n, err
+}
+
+// bindSources connects the streamed source allocated to stdin and the
+// output sink allocated to stdout to cmd, the others are bound in bindPipes.
+func (s *Stream) bindSources(cmd *exec.Cmd) {
+	sorted, _, err := s.topSort()
+	if err != nil {
+		panic(err)
+	}
+	fds := s.allocateFds(sorted)
+	for _, d := range sorted {
+		n := d.(*Node)
+		switch fd, ok := fds[n.Hash()]; {
+		case ok && fd == 0:
+			cmd.Stdin = s.pipeReader(n)
+		case ok && fd == 1:
+			cmd.Stdout = n.pipe.writer
+		}
+	}
+}
+
+// downloadSources downloads the seekable sources of the graph to temp files
+// of this run, keyed by node hash. The returned func removes them.
+func (s *Stream) downloadSources() (map[int]string, func(), error) {
+	files := map[int]string{}
+	cleanup := func() {
+		for _, f := range files {
+			_ = os.Remove(f)
+		}
+	}
+	for _, n := range s.nodesOfType("InputNode") {
+		if n.source != nil && n.source.err != nil {
+			return nil, cleanup, n.source.err
+		}
+		if n.source == nil || !n.source.seekable {
+			continue
+		}
+		f, err := n.source.download(s.Context)
+		if err != nil {
+			return nil, cleanup, err
+		}
+		files[n.Hash()] = f
+	}
+	return files, cleanup, nil
+}
+
+func openS3Source(ctx context.Context, url string, options KwArgs) (io.ReadCloser, error) {
+	fileL := strings.SplitN(strings.TrimPrefix(url, "s3://"), "/", 2)
+	if len(fileL) != 2 {
+		return nil, fmt.Errorf("s3 file format not valid: %s", url)
+	}
+	awsConfig := options.GetDefault("aws_config", &aws.Config{}).(*aws.Config)
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		return nil, err
+	}
+	out, err := s3.New(sess).GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(fileL[0]),
+		Key:    aws.String(fileL[1]),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
@@ -0,0 +1,76 @@
+package ffmpeg_go
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeS3 serves GetObject for the given objects, keyed by "bucket/key".
+func fakeS3(t *testing.T, objects map[string]string) *aws.Config {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, ok := objects[r.URL.Path[1:]]
+		if r.Method != http.MethodGet || !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(data))
+	}))
+	t.Cleanup(srv.Close)
+	return &aws.Config{
+		Credentials:      credentials.NewStaticCredentials("id", "secret", ""),
+		Endpoint:         aws.String(srv.URL),
+		Region:           aws.String("us-east-1"),
+		S3ForcePathStyle: aws.Bool(true),
+		DisableSSL:       aws.Bool(true),
+	}
+}
+
+func TestS3SourceStream(t *testing.T) {
+	config := fakeS3(t, map[string]string{"bucket/in.ts": "remote data"})
+	out := filepath.Join(t.TempDir(), "out.ts")
+	err := Input("s3://bucket/in.ts", KwArgs{"aws_config": config}).
+		Output(out).
+		SetFfmpegPath(fakeFfmpeg(t, `cat > "$3"`)).
+		Run()
+	assert.Nil(t, err)
+	data, _ := ioutil.ReadFile(out)
+	assert.Equal(t, "remote data", string(data))
+}
+
+func TestS3SourceSeekable(t *testing.T) {
+	config := fakeS3(t, map[string]string{"bucket/in.mp4": "remote data"})
+	out := filepath.Join(t.TempDir(), "out.mp4")
+	ffmpeg := fakeFfmpeg(t, `echo "$2" >> "$(dirname "$0")/inputs"; cp "$2" "$3"`)
+	run := Input("s3://bucket/in.mp4", KwArgs{"aws_config": config, "seekable": true}).Output(out).SetFfmpegPath(ffmpeg)
+	// every run downloads to a new temp file and removes it
+	assert.Nil(t, run.Run())
+	assert.Nil(t, run.Run())
+	data, _ := ioutil.ReadFile(out)
+	assert.Equal(t, "remote data", string(data))
+	inputs, err := ioutil.ReadFile(filepath.Join(filepath.Dir(ffmpeg), "inputs"))
+	assert.Nil(t, err)
+	files := strings.Fields(string(inputs))
+	assert.Len(t, files, 2)
+	assert.NotEqual(t, files[0], files[1])
+	for _, f := range files {
+		assert.Regexp(t, `/ffmpeg_go_\d+\.mp4$`, f)
+		assert.NoFileExists(t, f)
+	}
+}
+
+func TestS3SourceNotFound(t *testing.T) {
+	config := fakeS3(t, nil)
+	err := Input("s3://bucket/missing.ts", KwArgs{"aws_config": config}).
+		Output(filepath.Join(t.TempDir(), "out.ts")).
+		SetFfmpegPath(fakeFfmpeg(t, `cat > "$3"`)).
+		Run()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,52 @@
+package ffmpeg_go
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	RegisterSource("mem", func(ctx context.Context, url string, options KwArgs) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(strings.TrimPrefix(url, "mem://"))), nil
+	})
+}
+
+func TestRemoteSourceArgs(t *testing.T) {
+	args := Input("mem://data", KwArgs{"f": "mpegts"}).Output("out.mp4").GetArgs()
+	assert.Equal(t, []string{"-f", "mpegts", "-i", "pipe:", "out.mp4"}, args)
+
+	// the temp file is created by Run
+	args = Input("mem://data.mp4", KwArgs{"seekable": true}).Output("out.mp4").GetArgs()
+	assert.Equal(t, []string{"-i", "mem://data.mp4", "out.mp4"}, args)
+}
+
+func TestRemoteSourceSeekableOption(t *testing.T) {
+	for _, seekable := range []interface{}{1, "true"} {
+		var err error
+		assert.NotPanics(t, func() {
+			err = Input("mem://data.mp4", KwArgs{"seekable": seekable}).Output("out.mp4").Run()
+		})
+		assert.EqualError(t, err, fmt.Sprintf("invalid seekable %v of mem://data.mp4, must be a bool", seekable))
+	}
+}
+
+func TestRemoteSourceHash(t *testing.T) {
+	assert.NotEqual(t, Input("mem://a").Hash(), Input("mem://b").Hash())
+}
+
+func TestRemoteSourceExtraFds(t *testing.T) {
+	args := Concat([]*Stream{Input("mem://a"), Input("mem://b")}).Output("out.mp4").GetArgs()
+	assert.Equal(t, []string{
+		"-i", "pipe:", "-i", "pipe:3",
+		"-filter_complex", "[0][1]concat=n=2[s0]",
+		"-map", "[s0]", "out.mp4"}, args)
+
+	args = Input("mem://a").Output("out.mp4").WithInput(strings.NewReader("")).GetArgs()
+	assert.Equal(t, []string{"-i", "pipe:3", "out.mp4"}, args)
+}
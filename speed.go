@@ -0,0 +1,44 @@
+package ffmpeg_go
+
+import "fmt"
+
+type SpeedOptions struct {
+	// Interpolate generates the missing frames of slow motion with
+	// minterpolate instead of repeating frames.
+	Interpolate bool
+	// FPS is the frame rate minterpolate interpolates to, default "60".
+	FPS string
+	// InterpolateKwArgs are passed to minterpolate, e.g. {"mi_mode": "blend"}.
+	InterpolateKwArgs KwArgs
+}
+
+// ChangeSpeed plays s factor times faster, the video with “setpts=PTS/f“
+// and the audio with atempo, which keeps the pitch. If s is an input without
+// selector both video and audio are returned, otherwise only s is changed,
+// as audio if it is selected with "a" and as video else, and the other
+// result is nil.
+func (s *Stream) ChangeSpeed(factor float64, opts ...SpeedOptions) (video, audio *Stream) {
+	AssertType(s.Type, "FilterableStream", "change speed")
+	if factor <= 0 {
+		panic(fmt.Sprintf("invalid speed factor %v", factor))
+	}
+	opt := SpeedOptions{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	withVideo, withAudio := mediaOf(s)
+	if withVideo {
+		video = videoOf(s).Filter("setpts", Args{fmt.Sprintf("PTS/%s", formatFloat(factor))})
+		if opt.Interpolate && factor < 1 {
+			fps := opt.FPS
+			if fps == "" {
+				fps = "60"
+			}
+			video = video.Filter("minterpolate", nil, KwArgs{"fps": fps}, opt.InterpolateKwArgs)
+		}
+	}
+	if withAudio {
+		audio = atempoChain(audioOf(s), factor)
+	}
+	return video, audio
+}
@@ -0,0 +1,22 @@
+package ffmpeg_go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeSpeed(t *testing.T) {
+	video, audio := Input("in.mp4").ChangeSpeed(3)
+	args := Output([]*Stream{video, audio}, "out.mp4").GetArgs()
+	assert.Equal(t, "[0:v]setpts=PTS/3[s0];[0:a]atempo=2[s1];[s1]atempo=1.5[s2]", args[3])
+
+	video, audio = Input("in.mp4").Video().ChangeSpeed(0.25, SpeedOptions{Interpolate: true})
+	assert.Nil(t, audio)
+	assert.Equal(t, "[0:v]setpts=PTS/0.25[s0];[s0]minterpolate=fps=60[s1]", video.Output("out.mp4").GetArgs()[3])
+
+	_, audio = Input("in.mp4").Audio().ChangeSpeed(0.25)
+	assert.Equal(t, "[0:a]atempo=0.5[s0];[s0]atempo=0.5[s1]", audio.Output("out.wav").GetArgs()[3])
+
+	assert.Panics(t, func() { Input("in.mp4").ChangeSpeed(0) })
+}
@@ -0,0 +1,177 @@
+package ffmpeg_go
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+type SpriteOptions struct {
+	// Interval is the time between two thumbnails.
+	Interval time.Duration
+	// TileW and TileH are the size each thumbnail is scaled to, the aspect
+	// ratio isn't kept.
+	TileW, TileH int
+	// Columns and Rows are the number of thumbnails per sheet.
+	Columns, Rows int
+	// Dir is the directory the sheets and the WebVTT file are written to.
+	Dir string
+	// Pattern is the sheet file name in Dir, default "sprite_%03d.jpg".
+	Pattern string
+	// VTTName is the WebVTT file name in Dir, default "thumbnails.vtt".
+	VTTName string
+	// URLPrefix is prepended to the sheet file names in the WebVTT file,
+	// e.g. "https://cdn.example.com/video/".
+	URLPrefix string
+	// Duration of s, it is probed if s is a file input and measured by the
+	// run otherwise.
+	Duration time.Duration
+	// KwArgs are passed to the output verbatim, e.g. {"q:v": 5}.
+	KwArgs KwArgs
+}
+
+type SpriteCue struct {
+	Interval
+	// Sheet is the index of the sheet in Sprites.Sheets.
+	Sheet      int
+	X, Y, W, H int
+}
+
+type Sprites struct {
+	// Sheets are the paths of the written sheets.
+	Sheets []string
+	// VTT is the path of the written WebVTT file.
+	VTT  string
+	Cues []SpriteCue
+}
+
+// SpriteSheet samples a thumbnail of s every opts.Interval with fps, scales
+// it with scale and puts Columns x Rows thumbnails into each sheet with tile.
+// A WebVTT thumbnail track referencing the sheets with “#xywh=“ fragments is
+// written next to the sheets.
+func (s *Stream) SpriteSheet(ctx context.Context, opts SpriteOptions) (Sprites, error) {
+	AssertType(s.Type, "FilterableStream", "sprite sheet")
+	if opts.Interval <= 0 || opts.TileW <= 0 || opts.TileH <= 0 || opts.Columns <= 0 || opts.Rows <= 0 {
+		return Sprites{}, errors.New("sprite interval, tile size, columns and rows must be positive")
+	}
+	if opts.Dir == "" {
+		return Sprites{}, errors.New("sprite dir must be provided")
+	}
+	if opts.Pattern == "" {
+		opts.Pattern = "sprite_%03d.jpg"
+	}
+	if err := checkSpritePattern(opts.Pattern); err != nil {
+		return Sprites{}, err
+	}
+	if opts.VTTName == "" {
+		opts.VTTName = "thumbnails.vtt"
+	}
+	duration := opts.Duration
+	if fileName, ok := probeFileOf(s); ok && duration == 0 {
+		media, err := probeMedia(ctx, fileName)
+		if err != nil {
+			return Sprites{}, err
+		}
+		duration = media.Duration
+	}
+
+	sheets := videoOf(s).
+		Filter("fps", nil, KwArgs{"fps": fmt.Sprintf("1/%s", formatSeconds(opts.Interval))}).
+		Filter("scale", Args{fmt.Sprint(opts.TileW), fmt.Sprint(opts.TileH)}).
+		Filter("tile", Args{fmt.Sprintf("%dx%d", opts.Columns, opts.Rows)})
+	o := OutputContext(ctx, []*Stream{sheets}, filepath.Join(opts.Dir, opts.Pattern), opts.KwArgs)
+	o.FfmpegPath = s.FfmpegPath
+	parser := &durationParser{}
+	if err := runWithLogParser(o, parser.parse); err != nil {
+		return Sprites{}, err
+	}
+	if duration == 0 {
+		duration = parser.duration()
+	}
+
+	sprites := Sprites{VTT: filepath.Join(opts.Dir, opts.VTTName)}
+	sprites.Cues = spriteCues(duration, opts)
+	if len(sprites.Cues) > 0 {
+		for i := 0; i <= sprites.Cues[len(sprites.Cues)-1].Sheet; i++ {
+			// image2 numbers the files from 1
+			sprites.Sheets = append(sprites.Sheets, filepath.Join(opts.Dir, fmt.Sprintf(opts.Pattern, i+1)))
+		}
+	}
+	vtt := thumbnailVTT(sprites.Cues, func(sheet int) string {
+		return opts.URLPrefix + path.Base(filepath.ToSlash(sprites.Sheets[sheet]))
+	})
+	if err := ioutil.WriteFile(sprites.VTT, vtt, 0644); err != nil {
+		return Sprites{}, err
+	}
+	return sprites, nil
+}
+
+// spriteCues lays out one thumbnail per interval of duration, row by row.
+func spriteCues(duration time.Duration, opts SpriteOptions) []SpriteCue {
+	var cues []SpriteCue
+	perSheet := opts.Columns * opts.Rows
+	for i := 0; time.Duration(i)*opts.Interval < duration; i++ {
+		start := time.Duration(i) * opts.Interval
+		end := start + opts.Interval
+		if end > duration {
+			end = duration
+		}
+		n := i % perSheet
+		cues = append(cues, SpriteCue{
+			Interval: Interval{Start: start, End: end},
+			Sheet:    i / perSheet,
+			X:        n % opts.Columns * opts.TileW,
+			Y:        n / opts.Columns * opts.TileH,
+			W:        opts.TileW,
+			H:        opts.TileH,
+		})
+	}
+	return cues
+}
+
+func thumbnailVTT(cues []SpriteCue, url func(sheet int) string) []byte {
+	buf := bytes.NewBufferString("WEBVTT\n")
+	for _, c := range cues {
+		fmt.Fprintf(buf, "\n%s --> %s\n%s#xywh=%d,%d,%d,%d\n",
+			formatVTTTime(c.Start), formatVTTTime(c.End), url(c.Sheet), c.X, c.Y, c.W, c.H)
+	}
+	return buf.Bytes()
+}
+
+// formatVTTTime formats d as “HH:MM:SS.mmm“.
+func formatVTTTime(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+// checkSpritePattern checks that pattern numbers the sheets with a single
+// "%d" verb, e.g. "%03d", which both the image2 muxer and fmt understand.
+func checkSpritePattern(pattern string) error {
+	verbs := 0
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' {
+			continue
+		}
+		j := i + 1
+		for j < len(pattern) && '0' <= pattern[j] && pattern[j] <= '9' {
+			j++
+		}
+		switch {
+		case j == i+1 && j < len(pattern) && pattern[j] == '%':
+		case j < len(pattern) && pattern[j] == 'd':
+			verbs++
+		default:
+			return fmt.Errorf("invalid verb in sprite pattern %q", pattern)
+		}
+		i = j
+	}
+	if verbs != 1 {
+		return fmt.Errorf("sprite pattern %q must contain a single %%d", pattern)
+	}
+	return nil
+}
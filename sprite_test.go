@@ -0,0 +1,41 @@
+package ffmpeg_go
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThumbnailVTT(t *testing.T) {
+	cues := spriteCues(25*time.Second, SpriteOptions{Interval: 5 * time.Second, TileW: 160, TileH: 90, Columns: 2, Rows: 2})
+	assert.Len(t, cues, 5)
+	assert.Equal(t, SpriteCue{Interval: Interval{Start: 15 * time.Second, End: 20 * time.Second}, X: 160, Y: 90, W: 160, H: 90}, cues[3])
+	assert.Equal(t, 1, cues[4].Sheet)
+
+	cues = spriteCues(7500*time.Millisecond, SpriteOptions{Interval: 5 * time.Second, TileW: 160, TileH: 90, Columns: 2, Rows: 2})
+	vtt := thumbnailVTT(cues, func(sheet int) string { return "sprite.jpg" })
+	assert.Equal(t, `WEBVTT
+
+00:00:00.000 --> 00:00:05.000
+sprite.jpg#xywh=0,0,160,90
+
+00:00:05.000 --> 00:00:07.500
+sprite.jpg#xywh=160,0,160,90
+`, string(vtt))
+	assert.Equal(t, "01:01:01.001", formatVTTTime(time.Hour+time.Minute+time.Second+time.Millisecond))
+}
+
+func TestCheckSpritePattern(t *testing.T) {
+	assert.Nil(t, checkSpritePattern("sprite_%03d.jpg"))
+	assert.Nil(t, checkSpritePattern("100%%_%d.png"))
+	assert.EqualError(t, checkSpritePattern("sprite.jpg"), `sprite pattern "sprite.jpg" must contain a single %d`)
+	assert.EqualError(t, checkSpritePattern("%d_%d.jpg"), `sprite pattern "%d_%d.jpg" must contain a single %d`)
+	assert.EqualError(t, checkSpritePattern("%s.jpg"), `invalid verb in sprite pattern "%s.jpg"`)
+
+	_, err := Input("in.mp4").SpriteSheet(context.Background(), SpriteOptions{
+		Interval: time.Second, TileW: 160, TileH: 90, Columns: 2, Rows: 2, Dir: t.TempDir(), Pattern: "sprite.jpg",
+	})
+	assert.EqualError(t, err, `sprite pattern "sprite.jpg" must contain a single %d`)
+}
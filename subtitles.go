@@ -0,0 +1,153 @@
+package ffmpeg_go
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SubtitleStyle overrides the ASS style of burnt in subtitles with the
+// force_style option, zero values keep the style of the subtitles. Colours
+// are in the ASS format "&HAABBGGRR".
+type SubtitleStyle struct {
+	FontName      string
+	FontSize      int
+	PrimaryColour string
+	OutlineColour string
+	BackColour    string
+	Bold          bool
+	// Outline and Shadow are the widths of the outline and shadow.
+	Outline, Shadow int
+	// Alignment is the position in numpad layout, 2 is bottom center.
+	Alignment int
+	MarginV   int
+	// Extra are further ASS style fields, e.g. {"BorderStyle": "3"}.
+	Extra map[string]string
+}
+
+func (st SubtitleStyle) String() string {
+	fields := map[string]string{}
+	for k, v := range st.Extra {
+		fields[k] = v
+	}
+	set := func(k, v string) {
+		if v != "" {
+			fields[k] = v
+		}
+	}
+	setInt := func(k string, v int) {
+		if v != 0 {
+			fields[k] = fmt.Sprint(v)
+		}
+	}
+	set("FontName", st.FontName)
+	setInt("FontSize", st.FontSize)
+	set("PrimaryColour", st.PrimaryColour)
+	set("OutlineColour", st.OutlineColour)
+	set("BackColour", st.BackColour)
+	if st.Bold {
+		fields["Bold"] = "1"
+	}
+	setInt("Outline", st.Outline)
+	setInt("Shadow", st.Shadow)
+	setInt("Alignment", st.Alignment)
+	setInt("MarginV", st.MarginV)
+	var keys []string
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var r []string
+	for _, k := range keys {
+		r = append(r, k+"="+fields[k])
+	}
+	return strings.Join(r, ",")
+}
+
+// BurnSubtitles renders the subtitles of path into the video of s. ASS
+// subtitles without style overrides and kwargs are rendered with the ass
+// filter, all others with the subtitles filter and style as force_style.
+// kwargs are further options of subtitles, e.g. {"charenc": "CP1252"} or
+// {"si": 1} to pick the subtitle stream of a container.
+func (s *Stream) BurnSubtitles(path string, style SubtitleStyle, kwargs ...KwArgs) *Stream {
+	AssertType(s.Type, "FilterableStream", "burn subtitles")
+	args := MergeKwArgs(kwargs)
+	args["filename"] = path
+	forceStyle := style.String()
+	if forceStyle == "" && isASS(path) && len(args) == 1 {
+		return videoOf(s).Filter("ass", nil, args)
+	}
+	if forceStyle != "" {
+		args["force_style"] = forceStyle
+	}
+	return videoOf(s).Filter("subtitles", nil, args)
+}
+
+func isASS(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".ass" || ext == ".ssa"
+}
+
+// SoftSubtitle is a subtitle file muxed as a stream by OutputWithSubtitles.
+type SoftSubtitle struct {
+	Path string
+	// Language is the ISO 639-2 language code, e.g. "eng".
+	Language string
+	Title    string
+	// Default and Forced set the dispositions of the stream.
+	Default, Forced bool
+}
+
+// subtitleCodec returns the subtitle codec of the container of fileName for
+// the subtitles of path.
+func subtitleCodec(fileName, path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".mp4", ".m4v", ".mov":
+		return "mov_text", nil
+	case ".m3u8", ".webm", ".vtt":
+		return "webvtt", nil
+	case ".mkv", ".mka":
+		if isASS(path) {
+			return "ass", nil
+		}
+		return "srt", nil
+	}
+	return "", fmt.Errorf("soft subtitles in %s not supported", fileName)
+}
+
+// OutputWithSubtitles outputs streams to fileName with the subtitles added
+// as extra inputs. The codec is picked by the container: mov_text for mp4,
+// webvtt for HLS and WebM, srt or ass for Matroska. The language, title and
+// dispositions are set on the subtitle streams, which are numbered after
+// streams, so streams shouldn't contain subtitles themselves.
+func OutputWithSubtitles(streams []*Stream, subtitles []SoftSubtitle, fileName string, kwargs ...KwArgs) (*Stream, error) {
+	if len(streams) == 0 {
+		return nil, errors.New("no streams to output")
+	}
+	all := append([]*Stream(nil), streams...)
+	codecs := KwArgs{}
+	var tracks []Track
+	for i, sub := range subtitles {
+		codec, err := subtitleCodec(fileName, sub.Path)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, Input(sub.Path).Get("s"))
+		spec := fmt.Sprintf("s:%d", i)
+		codecs["c:"+spec] = codec
+		disposition := "0"
+		if sub.Default && sub.Forced {
+			disposition = "default+forced"
+		} else if sub.Default {
+			disposition = "default"
+		} else if sub.Forced {
+			disposition = "forced"
+		}
+		tracks = append(tracks, Track{Spec: spec, Language: sub.Language, Title: sub.Title, Disposition: disposition})
+	}
+	o := Output(all, fileName, append([]KwArgs{codecs, TrackKwArgs(tracks...)}, kwargs...)...)
+	o.FfmpegPath = streams[0].FfmpegPath
+	return o, nil
+}
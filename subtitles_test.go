@@ -0,0 +1,44 @@
+package ffmpeg_go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBurnSubtitles(t *testing.T) {
+	args := Input("in.mp4").BurnSubtitles(`C:\subs\it's.srt`, SubtitleStyle{
+		FontName: "DejaVu Sans", FontSize: 24, Outline: 2, Extra: map[string]string{"BorderStyle": "3"},
+	}).Output("out.mp4").GetArgs()
+	assert.Equal(t, `[0:v]subtitles=filename=C\\:\\\\subs\\\\it\\\'s.srt:`+
+		`force_style=BorderStyle=3\,FontName=DejaVu Sans\,FontSize=24\,Outline=2[s0]`, args[3])
+
+	args = Input("in.mp4").BurnSubtitles("subs.ass", SubtitleStyle{}).Output("out.mp4").GetArgs()
+	assert.Equal(t, "[0:v]ass=filename=subs.ass[s0]", args[3])
+
+	args = Input("in.mkv").BurnSubtitles("in.mkv", SubtitleStyle{}, KwArgs{"si": 1}).Output("out.mp4").GetArgs()
+	assert.Equal(t, "[0:v]subtitles=filename=in.mkv:si=1[s0]", args[3])
+}
+
+func TestOutputWithSubtitles(t *testing.T) {
+	in := Input("in.mp4")
+	o, err := OutputWithSubtitles([]*Stream{in.Video(), in.Audio()}, []SoftSubtitle{
+		{Path: "en.srt", Language: "eng", Default: true},
+		{Path: "de.srt", Language: "ger", Title: "Deutsch"},
+	}, "out.mp4", KwArgs{"c:v": "copy"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"-i", "in.mp4", "-i", "en.srt", "-i", "de.srt",
+		"-map", "0:v", "-map", "0:a", "-map", "1:s", "-map", "2:s",
+		"-c:s:0", "mov_text", "-c:s:1", "mov_text", "-c:v", "copy",
+		"-disposition:s:0", "default", "-disposition:s:1", "0",
+		"-metadata:s:s:0", "language=eng", "-metadata:s:s:1", "language=ger", "-metadata:s:s:1", "title=Deutsch",
+		"out.mp4"}, o.GetArgs())
+
+	o, err = OutputWithSubtitles([]*Stream{in}, []SoftSubtitle{{Path: "en.ass", Forced: true}}, "out.mkv")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"-i", "in.mp4", "-i", "en.ass", "-map", "0", "-map", "1:s",
+		"-c:s:0", "ass", "-disposition:s:0", "forced", "out.mkv"}, o.GetArgs())
+
+	_, err = OutputWithSubtitles([]*Stream{in}, []SoftSubtitle{{Path: "en.srt"}}, "out.avi")
+	assert.EqualError(t, err, "soft subtitles in out.avi not supported")
+}
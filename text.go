@@ -0,0 +1,89 @@
+package ffmpeg_go
+
+type TextOptions struct {
+	// Text is drawn literally, unless Expand is set.
+	Text string
+	// TextFile is a file the text is read from instead of Text.
+	TextFile string
+	// Expand enables the expansion functions of drawtext in the text, e.g.
+	// "%{pts:hms}" or "%{localtime:%X}", a literal % must then be written
+	// as "\%".
+	Expand bool
+	// X and Y are the position expressions, e.g. "(w-text_w)/2", default 0.
+	X, Y string
+	// FontFile is the path of the font, Font a fontconfig pattern.
+	FontFile, Font string
+	FontSize       int
+	FontColor      string
+	// Box draws a box of BoxColor around the text, BoxBorderW wide.
+	Box        bool
+	BoxColor   string
+	BoxBorderW int
+	// BorderW is the width of the border around the glyphs.
+	BorderW     int
+	BorderColor string
+	// ShadowX and ShadowY are the offset of the shadow of the text.
+	ShadowX, ShadowY int
+	ShadowColor      string
+	// KwArgs are further drawtext options, they override the ones above.
+	KwArgs KwArgs
+}
+
+func (o TextOptions) kwargs() KwArgs {
+	args := KwArgs{}
+	set := func(k, v string) {
+		if v != "" {
+			args[k] = v
+		}
+	}
+	setInt := func(k string, v int) {
+		if v != 0 {
+			args[k] = v
+		}
+	}
+	if o.Expand {
+		set("text", o.Text)
+	} else {
+		set("text", escapeDrawtext(o.Text))
+		if o.TextFile != "" {
+			args["expansion"] = "none"
+		}
+	}
+	set("textfile", o.TextFile)
+	set("x", o.X)
+	set("y", o.Y)
+	set("fontfile", o.FontFile)
+	set("font", o.Font)
+	setInt("fontsize", o.FontSize)
+	set("fontcolor", o.FontColor)
+	if o.Box {
+		args["box"] = 1
+		set("boxcolor", o.BoxColor)
+		setInt("boxborderw", o.BoxBorderW)
+	}
+	setInt("borderw", o.BorderW)
+	set("bordercolor", o.BorderColor)
+	setInt("shadowx", o.ShadowX)
+	setInt("shadowy", o.ShadowY)
+	set("shadowcolor", o.ShadowColor)
+	for k, v := range o.KwArgs {
+		args[k] = v
+	}
+	return args
+}
+
+// TextOverlay draws text on s with drawtext. The text is escaped for
+// drawtext on top of the filter graph escaping, so any caption is drawn as
+// is.
+func (s *Stream) TextOverlay(opts TextOptions) *Stream {
+	AssertType(s.Type, "FilterableStream", "drawtext")
+	if (opts.Text == "") == (opts.TextFile == "") {
+		panic("drawtext needs either text or a text file")
+	}
+	return NewFilterNode("drawtext", []*Stream{s}, 1, nil, opts.kwargs()).Stream("", "")
+}
+
+// escapeDrawtext escapes the text of drawtext so that it isn't expanded.
+func escapeDrawtext(text string) string {
+	return escapeChars(text, "\\%")
+}
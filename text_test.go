@@ -0,0 +1,35 @@
+package ffmpeg_go
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextOverlay(t *testing.T) {
+	args := Input("in.mp4").TextOverlay(TextOptions{
+		Text:     `It's 100% a:b \ [x], y;`,
+		X:        "(w-text_w)/2",
+		FontFile: `C:\fonts\a.ttf`,
+		FontSize: 24,
+		Box:      true,
+		BoxColor: "black@0.5",
+		BorderW:  2,
+		ShadowX:  1,
+	}).Output("out.mp4").GetArgs()
+	assert.Equal(t, `[0]drawtext=borderw=2:box=1:boxcolor=black@0.5:fontfile=C\\:\\\\fonts\\\\a.ttf:fontsize=24:shadowx=1:`+
+		`text=It\\\'s 100\\\\% a\\:b \\\\\\\\ \[x\]\, y\;:x=(w-text_w)/2[s0]`, args[3])
+
+	args = Input("in.mp4").TextOverlay(TextOptions{Text: "%{pts:hms}", Expand: true}).Output("out.mp4").GetArgs()
+	assert.Equal(t, `[0]drawtext=text=%{pts\\:hms}[s0]`, args[3])
+
+	args = Input("in.mp4").TextOverlay(TextOptions{TextFile: "caption.txt"}).Output("out.mp4").GetArgs()
+	assert.Equal(t, "[0]drawtext=expansion=none:textfile=caption.txt[s0]", args[3])
+
+	assert.Panics(t, func() { Input("in.mp4").TextOverlay(TextOptions{}) })
+}
+
+func TestDrawtextEscape(t *testing.T) {
+	args := Input("in.mp4").Drawtext("50% off: \"now\"", 10, 20, true).Output("out.mp4").GetArgs()
+	assert.Equal(t, `[0]drawtext=text=50\\\\% off\\: "now":x=10:y=20[s0]`, args[3])
+}
@@ -0,0 +1,291 @@
+package ffmpeg_go
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Clip is a part of a source in a Timeline.
+type Clip struct {
+	// Source is the input the clip is cut from.
+	Source *Stream
+	// In and Out are the points of the clip in Source, a zero Out is the end
+	// of Source, which is probed.
+	In, Out time.Duration
+	// Speed is the playback speed, 0 plays at normal speed.
+	Speed float64
+	// Volume is the gain of the audio, 0 leaves the volume unchanged.
+	Volume float64
+	// Mute silences the audio of the clip.
+	Mute bool
+	// Transition is the xfade transition into the next clip, e.g. "fade" or
+	// "wipeleft", the audio is crossfaded with acrossfade. Empty cuts.
+	Transition string
+	// TransitionDuration is the length of Transition, default one second.
+	TransitionDuration time.Duration
+	// Gap makes the clip black and silent for Out-In, Source isn't used.
+	Gap bool
+}
+
+// duration is the length of the clip on the timeline.
+func (c Clip) duration() time.Duration {
+	d := c.Out - c.In
+	if c.Speed > 0 {
+		d = time.Duration(float64(d) / c.Speed)
+	}
+	return d
+}
+
+func (c Clip) transitionDuration() time.Duration {
+	if c.Transition == "" {
+		return 0
+	}
+	if c.TransitionDuration > 0 {
+		return c.TransitionDuration
+	}
+	return time.Second
+}
+
+// Timeline assembles clips of one or more sources into a single video and
+// audio stream. All clips are converted to the same size, frame rate and
+// sample rate first, as concat and xfade require identical inputs.
+type Timeline struct {
+	Clips []Clip
+	// Width and Height of the output, the clips are scaled to fit and
+	// padded. The size of the first clip is probed if not set.
+	Width, Height int
+	// FrameRate of the output, the rate of the first clip is probed if not
+	// set, "30" if it can't be probed.
+	FrameRate string
+	// SampleRate of the output, default 48000.
+	SampleRate int
+	// NoAudio leaves out audio, it must be set if a source has no audio.
+	NoAudio bool
+}
+
+// duration is the length of the compiled timeline, the transitions overlap
+// the clips.
+func (t Timeline) duration() time.Duration {
+	var d time.Duration
+	for i, c := range t.Clips {
+		d += c.duration()
+		if i+1 < len(t.Clips) {
+			d -= c.transitionDuration()
+		}
+	}
+	return d
+}
+
+// source returns the source of the first clip that isn't a gap.
+func (t Timeline) source() (*Stream, bool) {
+	for _, c := range t.Clips {
+		if !c.Gap {
+			return c.Source, true
+		}
+	}
+	return nil, false
+}
+
+// Compile returns the video and audio streams of the timeline, audio is nil
+// with NoAudio. ctx is used to probe missing Out points and output formats.
+func (t Timeline) Compile(ctx context.Context) (video, audio *Stream, err error) {
+	if len(t.Clips) == 0 {
+		return nil, nil, errors.New("timeline has no clips")
+	}
+	clips := make([]Clip, len(t.Clips))
+	copy(clips, t.Clips)
+	for i := range clips {
+		if clips[i].Source == nil && !clips[i].Gap {
+			return nil, nil, fmt.Errorf("clip %d has no source", i)
+		}
+		if clips[i].Out == 0 && !clips[i].Gap {
+			if clips[i].Out, err = probeClipEnd(ctx, clips[i].Source); err != nil {
+				return nil, nil, fmt.Errorf("clip %d: %w", i, err)
+			}
+		}
+		if clips[i].Out <= clips[i].In {
+			return nil, nil, fmt.Errorf("clip %d ends before it starts", i)
+		}
+	}
+	for i := 0; i+1 < len(clips); i++ {
+		if d := clips[i].transitionDuration(); d > clips[i].duration() || d > clips[i+1].duration() {
+			return nil, nil, fmt.Errorf("transition %d is longer than its clips", i)
+		}
+	}
+	if err := t.probeFormat(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	var duration time.Duration
+	for i, c := range clips {
+		v, a := t.clipStreams(c)
+		if i == 0 {
+			video, audio, duration = v, a, c.duration()
+			continue
+		}
+		prev := clips[i-1]
+		td := prev.transitionDuration()
+		if td == 0 {
+			if audio != nil {
+				joined := Concat([]*Stream{video, audio, v, a}, KwArgs{"v": 1, "a": 1}).Node
+				video, audio = joined.Get("0"), joined.Get("1")
+			} else {
+				video = Concat([]*Stream{video, v})
+			}
+			duration += c.duration()
+			continue
+		}
+		video = Filter([]*Stream{video, v}, "xfade", nil, KwArgs{
+			"transition": prev.Transition,
+			"duration":   formatSeconds(td),
+			"offset":     formatSeconds(duration - td),
+		})
+		if audio != nil {
+			audio = Filter([]*Stream{audio, a}, "acrossfade", nil, KwArgs{"d": formatSeconds(td)})
+		}
+		duration += c.duration() - td
+	}
+	return video, audio, nil
+}
+
+// Output compiles the timeline and outputs it to fileName.
+func (t Timeline) Output(ctx context.Context, fileName string, kwargs ...KwArgs) (*Stream, error) {
+	video, audio, err := t.Compile(ctx)
+	if err != nil {
+		return nil, err
+	}
+	streams := []*Stream{video}
+	if audio != nil {
+		streams = append(streams, audio)
+	}
+	o := OutputContext(ctx, streams, fileName, kwargs...)
+	if s, ok := t.source(); ok {
+		o.FfmpegPath = s.FfmpegPath
+	}
+	return o, nil
+}
+
+// probeClipEnd returns the duration of a file input.
+func probeClipEnd(ctx context.Context, s *Stream) (time.Duration, error) {
+	fileName, ok := probeFileOf(s)
+	if !ok {
+		return 0, errors.New("out point of a non file input must be set")
+	}
+	media, err := probeMedia(ctx, fileName)
+	if err != nil {
+		return 0, err
+	}
+	if media.Duration == 0 {
+		return 0, fmt.Errorf("duration of %s unknown", fileName)
+	}
+	return media.Duration, nil
+}
+
+// probeFormat fills in the output format from the first clip.
+func (t *Timeline) probeFormat(ctx context.Context) error {
+	if t.SampleRate == 0 {
+		t.SampleRate = 48000
+	}
+	if t.Width > 0 && t.Height > 0 && t.FrameRate != "" {
+		return nil
+	}
+	s, ok := t.source()
+	var fileName string
+	if ok {
+		fileName, ok = probeFileOf(s)
+	}
+	if !ok {
+		if t.Width <= 0 || t.Height <= 0 {
+			return errors.New("timeline size must be set if the first clip isn't a file")
+		}
+		t.FrameRate = "30"
+		return nil
+	}
+	media, err := probeMedia(ctx, fileName)
+	if err != nil {
+		return err
+	}
+	v, ok := media.stream("video")
+	if !ok {
+		return fmt.Errorf("%s has no video", fileName)
+	}
+	if t.Width <= 0 || t.Height <= 0 {
+		t.Width, t.Height = v.Width, v.Height
+	}
+	if t.FrameRate == "" {
+		t.FrameRate = v.FrameRate
+		if t.FrameRate == "" || t.FrameRate == "0/0" {
+			t.FrameRate = "30"
+		}
+	}
+	return nil
+}
+
+// clipStreams cuts a clip and converts it to the timeline format.
+func (t Timeline) clipStreams(c Clip) (video, audio *Stream) {
+	if c.Gap {
+		return t.gapStreams(c.duration())
+	}
+	trim := KwArgs{"start": formatSeconds(c.In), "end": formatSeconds(c.Out)}
+	pts := "PTS-STARTPTS"
+	if c.Speed > 0 && c.Speed != 1 {
+		pts = fmt.Sprintf("(PTS-STARTPTS)/%s", formatFloat(c.Speed))
+	}
+	w, h := fmt.Sprint(t.Width), fmt.Sprint(t.Height)
+	video = videoOf(c.Source).
+		Filter("trim", nil, trim).
+		Filter("setpts", Args{pts}).
+		Filter("scale", Args{w, h}, KwArgs{"force_original_aspect_ratio": "decrease"}).
+		Filter("pad", Args{w, h, "(ow-iw)/2", "(oh-ih)/2"}).
+		Filter("setsar", Args{"1"}).
+		Filter("fps", Args{t.FrameRate}).
+		Filter("format", Args{"yuv420p"})
+	if t.NoAudio {
+		return video, nil
+	}
+	audio = audioOf(c.Source).
+		Filter("atrim", nil, trim).
+		Filter("asetpts", Args{"PTS-STARTPTS"})
+	if c.Speed > 0 {
+		audio = atempoChain(audio, c.Speed)
+	}
+	if c.Mute {
+		audio = audio.Filter("volume", Args{"0"})
+	} else if c.Volume > 0 && c.Volume != 1 {
+		audio = audio.Filter("volume", Args{formatFloat(c.Volume)})
+	}
+	audio = audio.
+		Filter("aresample", Args{fmt.Sprint(t.SampleRate)}).
+		Filter("aformat", nil, KwArgs{"sample_fmts": "fltp", "channel_layouts": "stereo"})
+	return video, audio
+}
+
+// atempoChain changes the tempo of s by speed with atempo filters, each
+// within the 0.5 to 2 range older versions of atempo support.
+func atempoChain(s *Stream, speed float64) *Stream {
+	for speed > 2 {
+		s, speed = s.Filter("atempo", Args{"2"}), speed/2
+	}
+	for speed < 0.5 {
+		s, speed = s.Filter("atempo", Args{"0.5"}), speed/0.5
+	}
+	if speed != 1 {
+		s = s.Filter("atempo", Args{formatFloat(speed)})
+	}
+	return s
+}
+
+// gapStreams returns black video and silence of d in the timeline format.
+func (t Timeline) gapStreams(d time.Duration) (video, audio *Stream) {
+	video = Input(fmt.Sprintf("color=c=black:s=%dx%d:r=%s:d=%s", t.Width, t.Height, t.FrameRate, formatSeconds(d)), KwArgs{"f": "lavfi"}).
+		Filter("setsar", Args{"1"}).
+		Filter("format", Args{"yuv420p"})
+	if t.NoAudio {
+		return video, nil
+	}
+	audio = Input(fmt.Sprintf("anullsrc=r=%d:cl=stereo", t.SampleRate), KwArgs{"f": "lavfi", "t": formatSeconds(d)}).
+		Filter("aformat", nil, KwArgs{"sample_fmts": "fltp", "channel_layouts": "stereo"})
+	return video, audio
+}
@@ -0,0 +1,128 @@
+package ffmpeg_go
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeline(t *testing.T) {
+	in1, in2 := Input("in1.mp4"), Input("in2.mp4")
+	out, err := Timeline{
+		Clips: []Clip{
+			{Source: in1, In: time.Second, Out: 5 * time.Second, Transition: "fade", TransitionDuration: 500 * time.Millisecond},
+			{Source: in2, Out: 2 * time.Second, Speed: 4, Volume: 0.5},
+			{Source: in1, In: 10 * time.Second, Out: 12 * time.Second, Mute: true},
+		},
+		Width: 1280, Height: 720, FrameRate: "25",
+	}.Output(context.Background(), "out.mp4")
+	assert.Nil(t, err)
+	args := out.GetArgs()
+	assert.Equal(t, "-i", args[0])
+	graph := args[5]
+	for _, filter := range []string{
+		"[0:v]trim=end=5:start=1[s0];[s0]setpts=PTS-STARTPTS[s1];[s1]scale=1280:720:force_original_aspect_ratio=decrease[s2];" +
+			"[s2]pad=1280:720:(ow-iw)/2:(oh-ih)/2[s3];[s3]setsar=1[s4];[s4]fps=25[s5];[s5]format=yuv420p[s6]",
+		"setpts=(PTS-STARTPTS)/4",
+		"atempo=2[", "atempo=2,", "volume=0.5", "volume=0[",
+		"xfade=duration=0.5:offset=3.5:transition=fade",
+		"acrossfade=d=0.5",
+		"concat=a=1:n=2:v=1",
+		"aresample=48000[", "aformat=channel_layouts=stereo:sample_fmts=fltp",
+	} {
+		if strings.HasSuffix(filter, ",") {
+			assert.Equal(t, 2, strings.Count(graph, strings.TrimSuffix(filter, ",")), filter)
+			continue
+		}
+		assert.Contains(t, graph, filter)
+	}
+}
+
+func TestTimelineErrors(t *testing.T) {
+	_, _, err := Timeline{}.Compile(context.Background())
+	assert.EqualError(t, err, "timeline has no clips")
+	_, _, err = Timeline{Clips: []Clip{{Source: Input("in.mp4").Trim(), In: time.Second}}}.Compile(context.Background())
+	assert.EqualError(t, err, "clip 0: out point of a non file input must be set")
+	_, _, err = Timeline{Clips: []Clip{
+		{Source: Input("in.mp4"), Out: time.Second, Transition: "fade", TransitionDuration: 2 * time.Second},
+		{Source: Input("in.mp4"), Out: 5 * time.Second},
+	}}.Compile(context.Background())
+	assert.EqualError(t, err, "transition 0 is longer than its clips")
+}
+
+func TestAtempoChain(t *testing.T) {
+	args := atempoChain(Input("in.mp4").Audio(), 0.3).Output("out.wav").GetArgs()
+	assert.Contains(t, args, "[0:a]atempo=0.5[s0];[s0]atempo=0.6[s1]")
+}
+
+const testEDL = `TITLE: Highlights
+FCM: NON-DROP FRAME
+
+001  AX       V     C        00:00:10:00 00:00:15:00 01:00:00:00 01:00:05:00
+* FROM CLIP NAME: goal.mov
+001  AX       A     C        00:00:10:00 00:00:15:00 01:00:00:00 01:00:05:00
+002  AX       V     C        00:00:15:00 00:00:15:00 01:00:05:00 01:00:05:00
+002  BX       V     D    025 00:01:00:00 00:01:04:12 01:00:05:00 01:00:09:12
+* FROM CLIP NAME: save.mov
+M2   BX       050.0                00:01:00:00
+003  BX       V     W001 010 00:02:00:00 00:02:02:00 01:00:09:12 01:00:11:12
+004  AX       V     C        00:00:20:00 00:00:21:00 01:00:12:00 01:00:13:00
+`
+
+func TestParseEDL(t *testing.T) {
+	sources := map[string]*Stream{}
+	tl, err := ParseEDL(strings.NewReader(testEDL), 25, func(reel, clipName string) (*Stream, error) {
+		if clipName == "" {
+			clipName = reel + ".mov"
+		}
+		if sources[clipName] == nil {
+			sources[clipName] = Input(clipName)
+		}
+		return sources[clipName], nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "25", tl.FrameRate)
+	// the outgoing sides of the transitions are extended by their length,
+	// the 50 fps M2 event plays 8.96s of source in its 4.48s
+	assert.Equal(t, []Clip{
+		{Source: sources["goal.mov"], In: 10 * time.Second, Out: 16 * time.Second, Transition: "fade", TransitionDuration: time.Second},
+		{Source: sources["save.mov"], In: time.Minute, Out: time.Minute + 9760*time.Millisecond, Speed: 2,
+			Transition: "wipeleft", TransitionDuration: 400 * time.Millisecond},
+		{Source: sources["BX.mov"], In: 2 * time.Minute, Out: 2*time.Minute + 2*time.Second},
+		{Gap: true, Out: 520 * time.Millisecond},
+		{Source: sources["AX.mov"], In: 20 * time.Second, Out: 21 * time.Second},
+	}, tl.Clips)
+	// the record timecodes run from 01:00:00:00 to 01:00:13:00
+	assert.Equal(t, 13*time.Second, tl.duration())
+
+	tl.Width, tl.Height = 1280, 720
+	video, audio, err := tl.Compile(context.Background())
+	assert.Nil(t, err)
+	graph := Output([]*Stream{video, audio}, "out.mp4").GetArgs()
+	assert.Contains(t, graph, "color=c=black:s=1280x720:r=25:d=0.52")
+	assert.Contains(t, strings.Join(graph, " "), "xfade=duration=1:offset=5:transition=fade")
+	assert.Contains(t, strings.Join(graph, " "), "xfade=duration=0.4:offset=9.48:transition=wipeleft")
+
+	// an outgoing side that doesn't continue the previous clip is a clip
+	tl, err = ParseEDL(strings.NewReader(`001  AX       V     C        00:00:10:00 00:00:15:00 01:00:00:00 01:00:05:00
+002  CX       V     C        00:00:30:00 00:00:30:00 01:00:05:00 01:00:05:00
+002  BX       V     D    025 00:01:00:00 00:01:03:00 01:00:05:00 01:00:08:00
+`), 25, func(reel, clipName string) (*Stream, error) {
+		return Input(reel + ".mov"), nil
+	})
+	assert.Nil(t, err)
+	assert.Len(t, tl.Clips, 3)
+	assert.Equal(t, 30*time.Second, tl.Clips[1].In)
+	assert.Equal(t, 31*time.Second, tl.Clips[1].Out)
+	assert.Equal(t, "fade", tl.Clips[1].Transition)
+	assert.Equal(t, 8*time.Second, tl.duration())
+
+	_, err = ParseEDL(strings.NewReader(testEDL), 25, func(reel, clipName string) (*Stream, error) {
+		return nil, errors.New("not found")
+	})
+	assert.EqualError(t, err, "edl event 001: not found")
+}
@@ -0,0 +1,173 @@
+package ffmpeg_go
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type DecodeErrorKind string
+
+const (
+	DecodeErrorCorruptMacroblock DecodeErrorKind = "corrupt_macroblock"
+	DecodeErrorMissingReference  DecodeErrorKind = "missing_reference"
+	DecodeErrorInvalidNAL        DecodeErrorKind = "invalid_nal"
+	DecodeErrorInvalidData       DecodeErrorKind = "invalid_data"
+	DecodeErrorOther             DecodeErrorKind = "other"
+)
+
+type DecodeError struct {
+	// Stream is the input stream, e.g. "0:1", empty if it can't be told
+	// from the log.
+	Stream string
+	// Context is the component which logged the error, e.g. "h264".
+	Context string
+	// Time is the progress time when the error was logged, which is close
+	// to but not exactly the timestamp of the broken frame.
+	Time    time.Duration
+	Kind    DecodeErrorKind
+	Message string
+}
+
+type DecodeReport struct {
+	// OK is true if the input decoded without any error.
+	OK     bool
+	Errors []DecodeError
+	// Counts are the number of errors by kind, StreamCounts by stream.
+	Counts       map[DecodeErrorKind]int
+	StreamCounts map[string]int
+}
+
+type VerifyOptions struct {
+	// ExitOnError stops at the first error (“-xerror“).
+	ExitOnError bool
+}
+
+// VerifyDecodable decodes all streams of input to the null muxer and
+// collects the errors logged by the decoders and demuxer.
+//
+// A failing ffmpeg run is returned as error if the input couldn't be opened,
+// e.g. because it doesn't exist, or if no decode error was logged, e.g. if
+// ffmpeg can't be found; otherwise it is reported as not OK.
+func VerifyDecodable(ctx context.Context, input *Stream, opts ...VerifyOptions) (DecodeReport, error) {
+	opt := VerifyOptions{}
+	if len(opts) > 0 {
+		opt = opts[0]
+	}
+	// "repeat" keeps repeated errors from being collapsed, "level" tags lines
+	// with their log level
+	globalArgs := []string{"-loglevel", "repeat+level+info"}
+	if opt.ExitOnError {
+		globalArgs = append(globalArgs, "-xerror")
+	}
+	args := KwArgs{}
+	if input.Node.nodeType == "InputNode" && input.Selector == "" {
+		// decode all streams instead of the best video and audio stream
+		args["map"] = "0"
+	}
+	out := nullOutput(ctx, input.FfmpegPath, []*Stream{input}, args).GlobalArgs(globalArgs...)
+	out.Context = ctx
+	out.FfmpegPath = input.FfmpegPath
+	parser := newDecodeErrorParser()
+	err := runWithLogParser(out, parser.parse)
+	report := parser.report()
+	if err != nil && (!parser.opened || len(report.Errors) == 0) {
+		return DecodeReport{}, err
+	}
+	report.OK = err == nil && len(report.Errors) == 0
+	return report, nil
+}
+
+var (
+	logContextRegexp   = regexp.MustCompile(`^\[([^\]@]+?) @ 0x[0-9a-f]+\] `)
+	inputStreamRegexp  = regexp.MustCompile(`^[a-z]ist#(\d+:\d+)/(\w+)$`)
+	logLevelRegexp     = regexp.MustCompile(`\[(error|fatal|panic)\] `)
+	logLevelTagRegexp  = regexp.MustCompile(`^\[(trace|debug|verbose|info|warning|error|fatal|panic)\] `)
+	streamInfoRegexp   = regexp.MustCompile(`Stream #(\d+:\d+)\S*: (?:Video|Audio|Subtitle): (\w+)`)
+	decodeStreamRegexp = regexp.MustCompile(`Error while decoding stream #(\d+:\d+): (.*)`)
+)
+
+// decodeErrorParser parses error lines tagged by “-loglevel level“, e.g.
+//
+//	[h264 @ 0x7f9] [error] concealing 1522 DC, 1522 AC, 1522 MV errors in P frame
+//	[error] Error while decoding stream #0:0: Invalid data found when processing input
+//	[vist#0:0/h264 @ 0x7f9] [error] Decoding error: Invalid data found when processing input
+//
+// Errors of a decoder are assigned to a stream if the input has exactly one
+// stream of that codec.
+type decodeErrorParser struct {
+	errors       []DecodeError
+	codecStreams map[string][]string
+	duration     durationParser
+	// opened is set once the input is opened and decoding starts
+	opened bool
+}
+
+func newDecodeErrorParser() *decodeErrorParser {
+	return &decodeErrorParser{codecStreams: map[string][]string{}}
+}
+
+func (p *decodeErrorParser) parse(line string) {
+	// lines without context start with their level tag, e.g. "[info] frame="
+	untagged := logLevelTagRegexp.ReplaceAllString(line, "")
+	p.duration.parse(untagged)
+	if strings.HasPrefix(untagged, "Input #") || p.duration.progress > 0 {
+		p.opened = true
+	}
+	if m := streamInfoRegexp.FindStringSubmatch(line); m != nil {
+		p.opened = true
+		p.codecStreams[m[2]] = append(p.codecStreams[m[2]], m[1])
+		return
+	}
+	if !logLevelRegexp.MatchString(line) {
+		return
+	}
+	e := DecodeError{Time: p.duration.progress}
+	if m := logContextRegexp.FindStringSubmatch(line); m != nil {
+		e.Context = m[1]
+		// ffmpeg 6.1+ logs decoding errors in the input stream context,
+		// e.g. [vist#0:0/h264 @ 0x7f9]
+		if m := inputStreamRegexp.FindStringSubmatch(e.Context); m != nil {
+			e.Stream, e.Context = m[1], m[2]
+		} else if streams := p.codecStreams[e.Context]; len(streams) == 1 {
+			e.Stream = streams[0]
+		}
+	}
+	e.Message = strings.TrimSpace(line[logLevelRegexp.FindStringIndex(line)[1]:])
+	if m := decodeStreamRegexp.FindStringSubmatch(e.Message); m != nil {
+		e.Stream, e.Message = m[1], m[2]
+	}
+	e.Kind = classifyDecodeError(e.Message)
+	p.errors = append(p.errors, e)
+}
+
+func (p *decodeErrorParser) report() DecodeReport {
+	r := DecodeReport{
+		Errors:       p.errors,
+		Counts:       map[DecodeErrorKind]int{},
+		StreamCounts: map[string]int{},
+	}
+	for _, e := range p.errors {
+		r.Counts[e.Kind]++
+		r.StreamCounts[e.Stream]++
+	}
+	return r
+}
+
+func classifyDecodeError(message string) DecodeErrorKind {
+	m := strings.ToLower(message)
+	switch {
+	case strings.Contains(m, "nal unit") || strings.Contains(m, "nal_unit"):
+		return DecodeErrorInvalidNAL
+	case strings.Contains(m, "concealing") || strings.Contains(m, "error while decoding mb") ||
+		strings.Contains(m, "corrupt"):
+		return DecodeErrorCorruptMacroblock
+	case strings.Contains(m, "reference") || strings.Contains(m, "co located") ||
+		strings.Contains(m, "no frame!"):
+		return DecodeErrorMissingReference
+	case strings.Contains(m, "invalid data"):
+		return DecodeErrorInvalidData
+	}
+	return DecodeErrorOther
+}
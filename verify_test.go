@@ -0,0 +1,49 @@
+package ffmpeg_go
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testDecodeLog is the log of “-loglevel repeat+level+info“.
+const testDecodeLog = `[info] Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'broken.mp4':
+[info]   Metadata:
+[info]     major_brand     : isom
+[info]   Duration: 00:00:10.00, start: 0.000000, bitrate: 1128 kb/s
+[info]   Stream #0:0[0x1](und): Video: h264 (High) (avc1 / 0x31637661), yuv420p(progressive), 1280x720, 1000 kb/s, 25 fps, 25 tbr, 12800 tbn (default)
+[info]   Stream #0:1[0x2](und): Audio: aac (LC) (mp4a / 0x6134706D), 44100 Hz, stereo, fltp, 128 kb/s (default)
+[info] Stream mapping:
+[info]   Stream #0:0 -> #0:0 (h264 (native) -> wrapped_avframe (native))
+[info]   Stream #0:1 -> #0:1 (aac (native) -> pcm_s16le (native))
+[info] Press [q] to stop, [?] for help
+[h264 @ 0x55d] [error] Invalid NAL unit size (1234 > 567).
+[info] Output #0, null, to 'pipe:':
+[info] frame=   50 fps=0.0 q=-0.0 size=N/A time=00:00:02.00 bitrate=N/A speed=4x
+[h264 @ 0x55d] [error] concealing 1522 DC, 1522 AC, 1522 MV errors in P frame
+[h264 @ 0x55d] [warning] mmco: unref short failure
+[error] Error while decoding stream #0:1: Invalid data found when processing input
+[info] frame=  100 fps=0.0 q=-0.0 size=N/A time=00:00:04.00 bitrate=N/A speed=4x
+[vist#0:0/h264 @ 0x7f9] [error] Decoding error: Missing reference picture`
+
+func TestDecodeErrorParser(t *testing.T) {
+	p := newDecodeErrorParser()
+	for _, line := range strings.Split(testDecodeLog, "\n") {
+		p.parse(line)
+	}
+	r := p.report()
+	assert.Equal(t, []DecodeError{
+		{Stream: "0:0", Context: "h264", Kind: DecodeErrorInvalidNAL, Message: "Invalid NAL unit size (1234 > 567)."},
+		{Stream: "0:0", Context: "h264", Time: 2 * time.Second, Kind: DecodeErrorCorruptMacroblock,
+			Message: "concealing 1522 DC, 1522 AC, 1522 MV errors in P frame"},
+		{Stream: "0:1", Time: 2 * time.Second, Kind: DecodeErrorInvalidData, Message: "Invalid data found when processing input"},
+		{Stream: "0:0", Context: "h264", Time: 4 * time.Second, Kind: DecodeErrorMissingReference,
+			Message: "Decoding error: Missing reference picture"},
+	}, r.Errors)
+	assert.Equal(t, map[string]int{"0:0": 3, "0:1": 1}, r.StreamCounts)
+	assert.Equal(t, 1, r.Counts[DecodeErrorCorruptMacroblock])
+	assert.True(t, p.opened)
+	assert.Equal(t, 4*time.Second, p.duration.duration())
+}